@@ -0,0 +1,257 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+)
+
+// conversionDataAnnotation stores the fields of v1beta1 that v1alpha1 cannot represent,
+// so they are not lost when a resource is read and written back in v1alpha1.
+const conversionDataAnnotation = "k8s.nginx.org/conversion-data"
+
+// conversionData contains the fields of the v1beta1 spec that have no v1alpha1 equivalent.
+type conversionData struct {
+	ReadyStatus            *v1beta1.ReadyStatus                `json:"readyStatus,omitempty"`
+	Pod                    *v1beta1.Pod                        `json:"pod,omitempty"`
+	WorkloadMigration      *v1beta1.WorkloadMigration          `json:"workloadMigration,omitempty"`
+	DefaultCertificate     *v1beta1.DefaultCertificate         `json:"defaultCertificate,omitempty"`
+	OperatorCA             *v1beta1.OperatorCA                 `json:"operatorCA,omitempty"`
+	CertManager            *v1beta1.CertManager                `json:"certManager,omitempty"`
+	DefaultIngressClass    bool                                `json:"defaultIngressClass,omitempty"`
+	IngressClassParameters *v1beta1.IngressClassParametersSpec `json:"ingressClassParameters,omitempty"`
+}
+
+var _ conversion.Convertible = &NginxIngressController{}
+
+// ConvertTo converts this NginxIngressController to the Hub version (v1beta1).
+func (src *NginxIngressController) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.NginxIngressController)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.NginxIngressControllerSpec{
+		Type:                  v1beta1.WorkloadKind(src.Spec.Type),
+		NginxPlus:             src.Spec.NginxPlus,
+		Image:                 v1beta1.Image(src.Spec.Image),
+		Replicas:              src.Spec.Replicas,
+		DefaultSecret:         convertNamespacedNameToObjectReference(src.Spec.DefaultSecret),
+		ServiceType:           src.Spec.ServiceType,
+		EnableCRDs:            src.Spec.EnableCRDs,
+		EnableSnippets:        src.Spec.EnableSnippets,
+		EnablePreviewPolicies: src.Spec.EnablePreviewPolicies,
+		IngressClass:          src.Spec.IngressClass,
+		Service:               (*v1beta1.Service)(src.Spec.Service),
+		WatchNamespace:        src.Spec.WatchNamespace,
+		HealthStatus:          (*v1beta1.HealthStatus)(src.Spec.HealthStatus),
+		NginxDebug:            src.Spec.NginxDebug,
+		LogLevel:              src.Spec.LogLevel,
+		ReportIngressStatus:   (*v1beta1.ReportIngressStatus)(src.Spec.ReportIngressStatus),
+		EnableLeaderElection:  src.Spec.EnableLeaderElection,
+		WildcardTLS:           convertNamespacedNameToObjectReference(src.Spec.WildcardTLS),
+		EnableLatencyMetrics:  src.Spec.EnableLatencyMetrics,
+		ConfigMapData:         src.Spec.ConfigMapData,
+		GlobalConfiguration:   convertNamespacedNameToObjectReference(src.Spec.GlobalConfiguration),
+		EnableTLSPassthrough:  src.Spec.EnableTLSPassthrough,
+		AppProtect:            (*v1beta1.AppProtect)(src.Spec.AppProtect),
+		AppProtectDos:         (*v1beta1.AppProtectDos)(src.Spec.AppProtectDos),
+		NginxReloadTimeout:    src.Spec.NginxReloadTimeout,
+	}
+
+	if src.Spec.NginxStatus != nil {
+		dst.Spec.NginxStatus = &v1beta1.NginxStatus{
+			Enable:     src.Spec.NginxStatus.Enable,
+			Port:       src.Spec.NginxStatus.Port,
+			AllowCidrs: splitAllowCidrs(src.Spec.NginxStatus.AllowCidrs),
+		}
+	}
+
+	if src.Spec.Prometheus != nil {
+		dst.Spec.Prometheus = &v1beta1.Prometheus{
+			Enable: src.Spec.Prometheus.Enable,
+			Port:   src.Spec.Prometheus.Port,
+			Secret: convertNamespacedNameToObjectReference(src.Spec.Prometheus.Secret),
+		}
+	}
+
+	dst.Status = v1beta1.NginxIngressControllerStatus{
+		Deployed:           src.Status.Deployed,
+		Conditions:         src.Status.Conditions,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		DesiredReplicas:    src.Status.DesiredReplicas,
+		AvailableReplicas:  src.Status.AvailableReplicas,
+		Image:              src.Status.Image,
+	}
+
+	if data, ok := src.Annotations[conversionDataAnnotation]; ok {
+		var restored conversionData
+		if err := json.Unmarshal([]byte(data), &restored); err != nil {
+			return fmt.Errorf("failed to parse the %v annotation: %w", conversionDataAnnotation, err)
+		}
+		dst.Spec.ReadyStatus = restored.ReadyStatus
+		dst.Spec.Pod = restored.Pod
+		dst.Spec.WorkloadMigration = restored.WorkloadMigration
+		dst.Spec.DefaultCertificate = restored.DefaultCertificate
+		dst.Spec.OperatorCA = restored.OperatorCA
+		dst.Spec.CertManager = restored.CertManager
+		dst.Spec.DefaultIngressClass = restored.DefaultIngressClass
+		dst.Spec.IngressClassParameters = restored.IngressClassParameters
+
+		dst.Annotations = copyAnnotationsWithout(src.Annotations, conversionDataAnnotation)
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *NginxIngressController) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.NginxIngressController)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = NginxIngressControllerSpec{
+		Type:                  string(src.Spec.Type),
+		NginxPlus:             src.Spec.NginxPlus,
+		Image:                 Image(src.Spec.Image),
+		Replicas:              src.Spec.Replicas,
+		DefaultSecret:         convertObjectReferenceToNamespacedName(src.Spec.DefaultSecret),
+		ServiceType:           src.Spec.ServiceType,
+		EnableCRDs:            src.Spec.EnableCRDs,
+		EnableSnippets:        src.Spec.EnableSnippets,
+		EnablePreviewPolicies: src.Spec.EnablePreviewPolicies,
+		IngressClass:          src.Spec.IngressClass,
+		Service:               (*Service)(src.Spec.Service),
+		WatchNamespace:        src.Spec.WatchNamespace,
+		HealthStatus:          (*HealthStatus)(src.Spec.HealthStatus),
+		NginxDebug:            src.Spec.NginxDebug,
+		LogLevel:              src.Spec.LogLevel,
+		ReportIngressStatus:   (*ReportIngressStatus)(src.Spec.ReportIngressStatus),
+		EnableLeaderElection:  src.Spec.EnableLeaderElection,
+		WildcardTLS:           convertObjectReferenceToNamespacedName(src.Spec.WildcardTLS),
+		EnableLatencyMetrics:  src.Spec.EnableLatencyMetrics,
+		ConfigMapData:         src.Spec.ConfigMapData,
+		GlobalConfiguration:   convertObjectReferenceToNamespacedName(src.Spec.GlobalConfiguration),
+		EnableTLSPassthrough:  src.Spec.EnableTLSPassthrough,
+		AppProtect:            (*AppProtect)(src.Spec.AppProtect),
+		AppProtectDos:         (*AppProtectDos)(src.Spec.AppProtectDos),
+		NginxReloadTimeout:    src.Spec.NginxReloadTimeout,
+	}
+
+	if src.Spec.NginxStatus != nil {
+		dst.Spec.NginxStatus = &NginxStatus{
+			Enable:     src.Spec.NginxStatus.Enable,
+			Port:       src.Spec.NginxStatus.Port,
+			AllowCidrs: strings.Join(src.Spec.NginxStatus.AllowCidrs, ","),
+		}
+	}
+
+	if src.Spec.Prometheus != nil {
+		dst.Spec.Prometheus = &Prometheus{
+			Enable: src.Spec.Prometheus.Enable,
+			Port:   src.Spec.Prometheus.Port,
+			Secret: convertObjectReferenceToNamespacedName(src.Spec.Prometheus.Secret),
+		}
+	}
+
+	// The status fields that are not available in v1alpha1 are not preserved: the status is reported again by the Operator
+	// on every reconcile.
+	dst.Status = NginxIngressControllerStatus{
+		Deployed:           src.Status.Deployed,
+		Conditions:         src.Status.Conditions,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		DesiredReplicas:    src.Status.DesiredReplicas,
+		AvailableReplicas:  src.Status.AvailableReplicas,
+		Image:              src.Status.Image,
+	}
+
+	preserved := conversionData{
+		ReadyStatus:            src.Spec.ReadyStatus,
+		Pod:                    src.Spec.Pod,
+		WorkloadMigration:      src.Spec.WorkloadMigration,
+		DefaultCertificate:     src.Spec.DefaultCertificate,
+		OperatorCA:             src.Spec.OperatorCA,
+		CertManager:            src.Spec.CertManager,
+		DefaultIngressClass:    src.Spec.DefaultIngressClass,
+		IngressClassParameters: src.Spec.IngressClassParameters,
+	}
+	if preserved != (conversionData{}) {
+		data, err := json.Marshal(preserved)
+		if err != nil {
+			return err
+		}
+		dst.Annotations = copyAnnotationsWithout(src.Annotations, conversionDataAnnotation)
+		dst.Annotations[conversionDataAnnotation] = string(data)
+	}
+
+	return nil
+}
+
+// copyAnnotationsWithout returns a copy of the annotations without the given key,
+// so the annotations of the converted resource are not shared with the source.
+func copyAnnotationsWithout(annotations map[string]string, key string) map[string]string {
+	result := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// convertNamespacedNameToObjectReference converts a reference in the namespace/name format.
+// A reference without a namespace is kept as a name, so it refers to the namespace of the NginxIngressController.
+func convertNamespacedNameToObjectReference(ref string) *v1beta1.ObjectReference {
+	if ref == "" {
+		return nil
+	}
+
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 1 {
+		return &v1beta1.ObjectReference{Name: parts[0]}
+	}
+
+	return &v1beta1.ObjectReference{Namespace: parts[0], Name: parts[1]}
+}
+
+func convertObjectReferenceToNamespacedName(ref *v1beta1.ObjectReference) string {
+	if ref == nil {
+		return ""
+	}
+
+	if ref.Namespace == "" {
+		return ref.Name
+	}
+
+	return ref.Namespace + "/" + ref.Name
+}
+
+// splitAllowCidrs splits the comma-separated list of IP/CIDR blocks.
+func splitAllowCidrs(cidrs string) []string {
+	var result []string
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr != "" {
+			result = append(result, cidr)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,197 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+)
+
+func TestConvertTo(t *testing.T) {
+	enable := true
+	replicas := int32(2)
+	port := uint16(9114)
+
+	src := &NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-nginx-ingress",
+			Namespace: "my-nginx-ingress",
+		},
+		Spec: NginxIngressControllerSpec{
+			Type:      "deployment",
+			NginxPlus: true,
+			Image: Image{
+				Repository: "nginx/nginx-ingress",
+				Tag:        "2.1.0",
+				PullPolicy: "Always",
+			},
+			Replicas:            &replicas,
+			DefaultSecret:       "my-namespace/my-secret",
+			EnableCRDs:          &enable,
+			WildcardTLS:         "my-wildcard",
+			GlobalConfiguration: "my-namespace/my-configuration",
+			NginxStatus: &NginxStatus{
+				Enable:     true,
+				AllowCidrs: "127.0.0.1, 10.0.0.0/8,",
+			},
+			Prometheus: &Prometheus{
+				Enable: true,
+				Port:   &port,
+				Secret: "my-namespace/my-prometheus",
+			},
+			AppProtect: &AppProtect{
+				Enable: true,
+			},
+		},
+		Status: NginxIngressControllerStatus{
+			Deployed:          true,
+			AvailableReplicas: 2,
+		},
+	}
+
+	expected := &v1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-nginx-ingress",
+			Namespace: "my-nginx-ingress",
+		},
+		Spec: v1beta1.NginxIngressControllerSpec{
+			Type:      v1beta1.WorkloadKindDeployment,
+			NginxPlus: true,
+			Image: v1beta1.Image{
+				Repository: "nginx/nginx-ingress",
+				Tag:        "2.1.0",
+				PullPolicy: "Always",
+			},
+			Replicas:            &replicas,
+			DefaultSecret:       &v1beta1.ObjectReference{Namespace: "my-namespace", Name: "my-secret"},
+			EnableCRDs:          &enable,
+			WildcardTLS:         &v1beta1.ObjectReference{Name: "my-wildcard"},
+			GlobalConfiguration: &v1beta1.ObjectReference{Namespace: "my-namespace", Name: "my-configuration"},
+			NginxStatus: &v1beta1.NginxStatus{
+				Enable:     true,
+				AllowCidrs: []string{"127.0.0.1", "10.0.0.0/8"},
+			},
+			Prometheus: &v1beta1.Prometheus{
+				Enable: true,
+				Port:   &port,
+				Secret: &v1beta1.ObjectReference{Namespace: "my-namespace", Name: "my-prometheus"},
+			},
+			AppProtect: &v1beta1.AppProtect{
+				Enable: true,
+			},
+		},
+		Status: v1beta1.NginxIngressControllerStatus{
+			Deployed:          true,
+			AvailableReplicas: 2,
+		},
+	}
+
+	dst := &v1beta1.NginxIngressController{}
+	if err := src.ConvertTo(dst); err != nil {
+		t.Fatalf("ConvertTo() returned unexpected error %v", err)
+	}
+	if diff := cmp.Diff(expected, dst); diff != "" {
+		t.Errorf("ConvertTo() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	tests := []struct {
+		spec NginxIngressControllerSpec
+		msg  string
+	}{
+		{
+			spec: NginxIngressControllerSpec{
+				Type: "daemonset",
+			},
+			msg: "empty spec",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				Type:                "deployment",
+				DefaultSecret:       "my-namespace/my-secret",
+				WildcardTLS:         "my-wildcard",
+				GlobalConfiguration: "my-namespace/my-configuration/extra",
+				NginxStatus: &NginxStatus{
+					Enable:     true,
+					AllowCidrs: "127.0.0.1,10.0.0.0/8",
+				},
+				Prometheus: &Prometheus{
+					Enable: true,
+					Secret: "my-namespace/my-prometheus",
+				},
+				ReportIngressStatus: &ReportIngressStatus{
+					Enable:      true,
+					IngressLink: "my-ingresslink",
+				},
+			},
+			msg: "references and cidrs",
+		},
+	}
+
+	for _, test := range tests {
+		src := &NginxIngressController{Spec: test.spec}
+
+		hub := &v1beta1.NginxIngressController{}
+		if err := src.ConvertTo(hub); err != nil {
+			t.Fatalf("ConvertTo() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+
+		dst := &NginxIngressController{}
+		if err := dst.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+
+		if diff := cmp.Diff(src, dst); diff != "" {
+			t.Errorf("round trip mismatch (-want +got) for the case of %v:\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestConvertHubRoundTrip(t *testing.T) {
+	hub := &v1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-nginx-ingress",
+			Namespace:   "my-nginx-ingress",
+			Annotations: map[string]string{"my-annotation": "value"},
+		},
+		Spec: v1beta1.NginxIngressControllerSpec{
+			Type: v1beta1.WorkloadKindDaemonSet,
+			Pod: &v1beta1.Pod{
+				NodeSelector:      map[string]string{"node-role.kubernetes.io/edge": ""},
+				PriorityClassName: "system-cluster-critical",
+			},
+			WorkloadMigration: &v1beta1.WorkloadMigration{
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			OperatorCA: &v1beta1.OperatorCA{
+				DefaultServer:  true,
+				WildcardDomain: "example.com",
+			},
+			DefaultIngressClass:    true,
+			IngressClassParameters: &v1beta1.IngressClassParametersSpec{GlobalConfiguration: &v1beta1.ObjectReference{Namespace: "nginx-ingress", Name: "nginx-configuration"}},
+		},
+	}
+
+	spoke := &NginxIngressController{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() returned unexpected error %v", err)
+	}
+	if _, ok := spoke.Annotations[conversionDataAnnotation]; !ok {
+		t.Errorf("ConvertFrom() did not set the %v annotation", conversionDataAnnotation)
+	}
+	if _, ok := hub.Annotations[conversionDataAnnotation]; ok {
+		t.Errorf("ConvertFrom() modified the annotations of the hub")
+	}
+
+	result := &v1beta1.NginxIngressController{}
+	if err := spoke.ConvertTo(result); err != nil {
+		t.Fatalf("ConvertTo() returned unexpected error %v", err)
+	}
+	if diff := cmp.Diff(hub, result); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -172,10 +172,38 @@ type NginxIngressControllerStatus struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	Deployed bool `json:"deployed"`
+	// Conditions represent the latest available observations of the NginxIngressController state.
+	// Known condition types are Ready, Progressing, Degraded, PrerequisitesMet and CRDsInstalled.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// The generation of the NginxIngressController observed by the Operator.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The number of Ingress Controller pods the workload is expected to run.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podCount"
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+	// The number of available Ingress Controller pods.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+	// The image the Ingress Controller workload is running.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Image string `json:"image,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+//+kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // NginxIngressController is the Schema for the nginxingresscontrollers API
 // +operator-sdk:csv:customresourcedefinitions:displayName="Nginx Ingress Controller",resources={{Pod,v1,nic-runner},{Deployment,v1,nic-deployment},{ReplicaSet,v1beta2,nic-replicaset}}
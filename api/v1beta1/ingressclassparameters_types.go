@@ -0,0 +1,56 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IngressClassParametersSpec defines the settings of an IngressClass of the Ingress Controller.
+type IngressClassParametersSpec struct {
+	// The GlobalConfiguration resource of the Ingress Controllers of the class. It is used by the Ingress Controllers whose
+	// NginxIngressController doesn't set globalConfiguration.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	GlobalConfiguration *ObjectReference `json:"globalConfiguration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// IngressClassParameters is the Schema for the ingressclassparameters API. It is created by the Operator from the
+// ingressClassParameters of the NginxIngressController resources and referenced by the parameters of their IngressClass.
+// +operator-sdk:csv:customresourcedefinitions:displayName="Ingress Class Parameters"
+type IngressClassParameters struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressClassParametersSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IngressClassParametersList contains a list of IngressClassParameters
+type IngressClassParametersList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IngressClassParameters `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IngressClassParameters{}, &IngressClassParametersList{})
+}
@@ -0,0 +1,641 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultIngressClass is the class of the Ingress Controller when ingressClass is not set.
+	DefaultIngressClass = "nginx"
+	// DefaultCertificateCommonName is the common name of the generated default certificate when commonName is not set.
+	DefaultCertificateCommonName = "example.com"
+	// DefaultCertificateDuration is the validity of the generated default certificate when duration is not set.
+	DefaultCertificateDuration = 365 * 24 * time.Hour
+	// DefaultCertificateRenewBefore is how long before the expiry the generated default certificate is regenerated
+	// when renewBefore is not set.
+	DefaultCertificateRenewBefore = 30 * 24 * time.Hour
+)
+
+// WorkloadKind is the kind of the workload that runs the Ingress Controller pods.
+// +kubebuilder:validation:Enum=deployment;daemonset
+type WorkloadKind string
+
+const (
+	// WorkloadKindDeployment runs the Ingress Controller as a Deployment.
+	WorkloadKindDeployment WorkloadKind = "deployment"
+	// WorkloadKindDaemonSet runs the Ingress Controller as a DaemonSet.
+	WorkloadKindDaemonSet WorkloadKind = "daemonset"
+)
+
+// KeyAlgorithm is the algorithm and size of the private key of a generated certificate.
+// +kubebuilder:validation:Enum=RSA-2048;RSA-4096;ECDSA-P256;ECDSA-P384;Ed25519
+type KeyAlgorithm string
+
+const (
+	// KeyAlgorithmRSA2048 is a 2048-bit RSA key.
+	KeyAlgorithmRSA2048 KeyAlgorithm = "RSA-2048"
+	// KeyAlgorithmRSA4096 is a 4096-bit RSA key.
+	KeyAlgorithmRSA4096 KeyAlgorithm = "RSA-4096"
+	// KeyAlgorithmECDSAP256 is an ECDSA key on the P-256 curve.
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+	// KeyAlgorithmECDSAP384 is an ECDSA key on the P-384 curve.
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSA-P384"
+	// KeyAlgorithmEd25519 is an Ed25519 key.
+	KeyAlgorithmEd25519 KeyAlgorithm = "Ed25519"
+)
+
+// NginxIngressControllerSpec defines the desired state of NginxIngressController
+type NginxIngressControllerSpec struct {
+	// The type of the Ingress Controller installation - deployment or daemonset.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Type WorkloadKind `json:"type"`
+	// Deploys the Ingress Controller for NGINX Plus. The default is false meaning the Ingress Controller will be deployed for NGINX OSS.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NginxPlus bool `json:"nginxPlus"`
+	// The image of the Ingress Controller.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Image Image `json:"image"`
+	// The number of replicas of the Ingress Controller pod. Can only be set if the type is set to deployment.
+	// When not set, the replicas of the Deployment are not managed by the Operator and can be scaled by other controllers,
+	// like a HorizontalPodAutoscaler.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Replicas *int32 `json:"replicas"`
+	// The TLS Secret for TLS termination of the default server.
+	// The secret must be of the type kubernetes.io/tls.
+	// If not specified, the operator will generate and deploy a TLS Secret with a self-signed certificate and key.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DefaultSecret *ObjectReference `json:"defaultSecret,omitempty"`
+	// The self-signed certificate the Operator generates for the default server when defaultSecret is not set.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DefaultCertificate *DefaultCertificate `json:"defaultCertificate,omitempty"`
+	// Certificates issued by the certificate authority managed by the Operator.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	OperatorCA *OperatorCA `json:"operatorCA,omitempty"`
+	// Certificates issued by cert-manager. Requires cert-manager installed in the cluster.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	CertManager *CertManager `json:"certManager,omitempty"`
+	// The type of the Service for the Ingress Controller. Valid Service types are: NodePort and LoadBalancer.
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ServiceType string `json:"serviceType"`
+	// Enables the use of NGINX Ingress Resource Definitions (VirtualServer and VirtualServerRoute). Default is true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnableCRDs *bool `json:"enableCRDs"`
+	// Enable custom NGINX configuration snippets in VirtualServer, VirtualServerRoute and TransportServer resources.
+	// Requires enableCRDs set to true.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnableSnippets bool `json:"enableSnippets"`
+	// Enables preview policies.
+	// Requires enableCRDs set to true.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnablePreviewPolicies bool `json:"enablePreviewPolicies"`
+	// A class of the Ingress controller. The Ingress controller only processes Ingress resources that belong to its
+	// class (in other words, have the annotation “kubernetes.io/ingress.class”). Default is `nginx`.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	IngressClass string `json:"ingressClass"`
+	// Marks the IngressClass of the Ingress Controller as the default class of the cluster with the
+	// `ingressclass.kubernetes.io/is-default-class` annotation. The Ingress resources created without a class are assigned to it.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DefaultIngressClass bool `json:"defaultIngressClass,omitempty"`
+	// The settings of the IngressClass of the Ingress Controller. The Operator renders them into an IngressClassParameters
+	// resource with the name of the class, referenced by the parameters of the IngressClass. The NginxIngressController
+	// resources that share a class must set the same settings.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	IngressClassParameters *IngressClassParametersSpec `json:"ingressClassParameters,omitempty"`
+	// The service of the Ingress controller.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Service *Service `json:"service"`
+	// Namespace to watch for Ingress resources. By default the Ingress controller watches all namespaces.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	WatchNamespace string `json:"watchNamespace"`
+	// Adds a new location to the default server. The location responds with the 200 status code for any request.
+	// Useful for external health-checking of the Ingress controller.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HealthStatus *HealthStatus `json:"healthStatus,omitempty"`
+	// Enable debugging for NGINX. Uses the nginx-debug binary. Requires ‘error-log-level: debug’ in the ConfigMapData.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NginxDebug bool `json:"nginxDebug"`
+	// Log level for V logs.
+	// Format is 0 - 3
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	LogLevel uint8 `json:"logLevel"`
+	// NGINX stub_status, or the NGINX Plus API.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NginxStatus *NginxStatus `json:"nginxStatus,omitempty"`
+	// Update the address field in the status of Ingresses resources.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ReportIngressStatus *ReportIngressStatus `json:"reportIngressStatus,omitempty"`
+	// Enables Leader election to avoid multiple replicas of the controller reporting the status of Ingress resources
+	// – only one replica will report status.
+	// Default is true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnableLeaderElection *bool `json:"enableLeaderElection"`
+	// A Secret with a TLS certificate and key for TLS termination of every Ingress host for which TLS termination is enabled but the Secret is not specified.
+	// The secret must be of the type kubernetes.io/tls.
+	// If the argument is not set, for such Ingress hosts NGINX will break any attempt to establish a TLS connection.
+	// If the argument is set, but the Ingress controller is not able to fetch the Secret from Kubernetes API, the Ingress Controller will fail to start.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	WildcardTLS *ObjectReference `json:"wildcardTLS,omitempty"`
+	// NGINX or NGINX Plus metrics in the Prometheus format.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Prometheus *Prometheus `json:"prometheus,omitempty"`
+	// Bucketed response times from when NGINX establishes a connection to an upstream server to when the last byte of the response body is received by NGINX.
+	// **Note** The metric for the upstream isn't available until traffic is sent to the upstream.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnableLatencyMetrics bool `json:"enableLatencyMetrics"`
+	// Initial values of the Ingress Controller ConfigMap.
+	// Check https://docs.nginx.com/nginx-ingress-controller/configuration/global-configuration/configmap-resource/ for
+	// more information about possible values.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ConfigMapData map[string]string `json:"configMapData,omitempty"`
+	// The GlobalConfiguration resource for global configuration of the Ingress Controller.
+	// Requires enableCRDs set to true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	GlobalConfiguration *ObjectReference `json:"globalConfiguration,omitempty"`
+	// Enable TLS Passthrough on port 443.
+	// Requires enableCRDs set to true.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnableTLSPassthrough bool `json:"enableTLSPassthrough"`
+	// App Protect support configuration.
+	// Requires enableCRDs set to true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AppProtect *AppProtect `json:"appProtect"`
+	// App Protect Dos support configuration.
+	// Requires enableCRDs set to true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AppProtectDos *AppProtectDos `json:"appProtectDos"`
+	// Timeout in milliseconds which the Ingress Controller will wait for a successful NGINX reload after a change or at the initial start.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NginxReloadTimeout int `json:"nginxReloadTimeout"`
+	// The readiness endpoint of the Ingress Controller and the probes of the Ingress Controller container that use it.
+	// By default the endpoint and the probes are enabled.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ReadyStatus *ReadyStatus `json:"readyStatus,omitempty"`
+	// The customization of the Ingress Controller pods.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Pod *Pod `json:"pod,omitempty"`
+	// The migration between the deployment and daemonset types.
+	// By default, when the type changes, the new workload must become available before the old one is removed.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	WorkloadMigration *WorkloadMigration `json:"workloadMigration,omitempty"`
+}
+
+// Condition types reported in the status of a NginxIngressController.
+const (
+	// ConditionReady is true when the Ingress Controller workload has all of its desired pods available.
+	ConditionReady = "Ready"
+	// ConditionProgressing is true while the Ingress Controller workload is being rolled out.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when the Operator failed to reconcile the NginxIngressController
+	// or the Ingress Controller workload is not able to reach its desired state.
+	ConditionDegraded = "Degraded"
+	// ConditionPrerequisitesMet is true when the resources required by the Ingress Controller
+	// (ServiceAccount, RBAC, IngressClass, default Secret, SCC) are in place.
+	ConditionPrerequisitesMet = "PrerequisitesMet"
+	// ConditionCRDsInstalled is true when the Ingress Controller CustomResourceDefinitions are installed.
+	ConditionCRDsInstalled = "CRDsInstalled"
+	// ConditionCRDsUpToDate is false when the Operator refused to update Ingress Controller CustomResourceDefinitions
+	// because of changes that can break the existing custom resources.
+	ConditionCRDsUpToDate = "CRDsUpToDate"
+	// ConditionClusterResourcesReady is true when the cluster-scoped resources required by the Ingress Controller (ClusterRole,
+	// ClusterRoleBinding, IngressClass, SCC) are installed. It is only reported when the Operator runs in namespaced mode.
+	ConditionClusterResourcesReady = "ClusterResourcesReady"
+	// ConditionWorkloadMigrating is true while the Ingress Controller is migrated between the deployment and daemonset types,
+	// from the creation of the new workload until the removal of the old one.
+	ConditionWorkloadMigrating = "WorkloadMigrating"
+	// ConditionCertificatesReady is true when the cert-manager Certificates of the Ingress Controller are ready.
+	// It is only reported when certManager is set.
+	ConditionCertificatesReady = "CertificatesReady"
+)
+
+// NginxIngressControllerStatus defines the observed state of NginxIngressController
+type NginxIngressControllerStatus struct {
+	// Deployed is true if the Operator has finished the deployment of the NginxIngressController.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Deployed bool `json:"deployed"`
+	// Conditions represent the latest available observations of the NginxIngressController state.
+	// Known condition types are Ready, Progressing, Degraded, PrerequisitesMet, CRDsInstalled, CRDsUpToDate, ClusterResourcesReady, WorkloadMigrating and CertificatesReady.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// The generation of the NginxIngressController observed by the Operator.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The number of Ingress Controller pods the workload is expected to run.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podCount"
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+	// The number of available Ingress Controller pods.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+	// The image the Ingress Controller workload is running.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Image string `json:"image,omitempty"`
+	// The expiry time of the self-signed certificate the Operator generated for the default server.
+	// Not set when defaultSecret is set.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	DefaultCertificateExpiry *metav1.Time `json:"defaultCertificateExpiry,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+//+kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NginxIngressController is the Schema for the nginxingresscontrollers API
+// +operator-sdk:csv:customresourcedefinitions:displayName="Nginx Ingress Controller",resources={{Pod,v1,nic-runner},{Deployment,v1,nic-deployment},{ReplicaSet,v1beta2,nic-replicaset}}
+type NginxIngressController struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NginxIngressControllerSpec   `json:"spec,omitempty"`
+	Status NginxIngressControllerStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NginxIngressControllerList contains a list of NginxIngressController
+type NginxIngressControllerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NginxIngressController `json:"items"`
+}
+
+// ObjectReference references a namespaced resource used by the Ingress Controller.
+type ObjectReference struct {
+	// The namespace of the resource. Defaults to the namespace of the NginxIngressController.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the resource.
+	Name string `json:"name"`
+}
+
+// Image defines the Repository, Tag and ImagePullPolicy of the Ingress Controller Image.
+type Image struct {
+	// The repository of the image.
+	Repository string `json:"repository"`
+	// The tag (version) of the image.
+	Tag string `json:"tag"`
+	// The ImagePullPolicy of the image.
+	// +kubebuilder:validation:Enum=Never;Always;IfNotPresent
+	PullPolicy string `json:"pullPolicy"`
+}
+
+// HealthStatus defines the health status of the Ingress Controller.
+type HealthStatus struct {
+	// Enable the HealthStatus.
+	Enable bool `json:"enable"`
+	// URI of the location. Default is `/nginx-health`.
+	// +kubebuilder:validation:Optional
+	URI string `json:"uri"`
+}
+
+// NginxStatus defines the NGINX Status of the Ingress Controller.
+type NginxStatus struct {
+	// Enable the NginxStatus.
+	Enable bool `json:"enable"`
+	// Set the port where the NGINX stub_status or the NGINX Plus API is exposed. Default is 8080.
+	// Format is 1023 - 65535
+	// +kubebuilder:validation:Minimum=1023
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Port *uint16 `json:"port"`
+	// Whitelist IPv4 IP/CIDR blocks to allow access to NGINX stub_status or the NGINX Plus API. (default “127.0.0.1”)
+	// +kubebuilder:validation:Optional
+	AllowCidrs []string `json:"allowCidrs,omitempty"`
+}
+
+// ReadyStatus defines the readiness endpoint of the Ingress Controller and the probes that use it.
+type ReadyStatus struct {
+	// Enable the readiness endpoint and the probes. Default is true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Enable *bool `json:"enable,omitempty"`
+	// Sets the port where the readiness endpoint is exposed. The endpoint is served at the /nginx-ready path.
+	// Default is 8081.
+	// Format is 1023 - 65535
+	// +kubebuilder:validation:Minimum=1023
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Port *uint16 `json:"port,omitempty"`
+	// The thresholds of the readiness probe.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
+	// The thresholds of the liveness probe.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	LivenessProbe *Probe `json:"livenessProbe,omitempty"`
+	// The thresholds of the startup probe.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	StartupProbe *Probe `json:"startupProbe,omitempty"`
+}
+
+// Probe defines the thresholds of a probe of the Ingress Controller container.
+// Fields that are not set use the defaults of the Operator for the probe.
+type Probe struct {
+	// Number of seconds after the container has started before the probe is initiated.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// How often (in seconds) to perform the probe.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// Number of seconds after which the probe times out.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// Minimum consecutive successes for the probe to be considered successful after having failed.
+	// Must be 1 for the liveness and startup probes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+	// Minimum consecutive failures for the probe to be considered failed after having succeeded.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// ReportIngressStatus defines the report of the status of the Ingress Resources.
+type ReportIngressStatus struct {
+	// Enable the ReportIngressStatus.
+	Enable bool `json:"enable"`
+	// Specifies the name of the service with the type LoadBalancer through which the Ingress controller pods are exposed externally.
+	// The external address of the service is used when reporting the status of Ingress resources.
+	// Note: if serviceType is LoadBalancer, the value of this field will be ignored, and the operator will use the name of the created LoadBalancer service instead.
+	// +kubebuilder:validation:Optional
+	ExternalService string `json:"externalService"`
+	// Specifies the name of the IngressLink resource, which exposes the Ingress Controller pods via a BIG-IP system.
+	// The IP of the BIG-IP system is used when reporting the status of Ingress, VirtualServer and VirtualServerRoute resources.
+	// Requires reportIngressStatus.enable set to true.
+	// Note: If serviceType is LoadBalancer or reportIngressStatus.externalService is set, the value of this field
+	// will be ignored.
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	IngressLink string `json:"ingressLink,omitempty"`
+}
+
+// Prometheus defines the Prometheus metrics for the Ingress Controller.
+type Prometheus struct {
+	// Enable Prometheus metrics.
+	Enable bool `json:"enable"`
+	// Sets the port where the Prometheus metrics are exposed. Default is 9113.
+	// Format is 1023 - 65535
+	// +kubebuilder:validation:Minimum=1023
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Port *uint16 `json:"port"`
+	// A Secret with a TLS certificate and key for TLS termination of the Prometheus endpoint.
+	// The secret must be of the type kubernetes.io/tls.
+	// If specified, but the Ingress controller is not able to fetch the Secret from Kubernetes API,
+	// the Ingress Controller will fail to start.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Secret *ObjectReference `json:"secret,omitempty"`
+}
+
+// AppProtect support configuration.
+type AppProtect struct {
+	// Enable App Protect WAF.
+	Enable bool `json:"enable"`
+}
+
+// AppProtectDos support configuration.
+type AppProtectDos struct {
+	// Enable App Protect Dos.
+	Enable bool `json:"enable"`
+	// Enable debug mode.
+	Debug bool `json:"debug,omitempty"`
+	// Max number of ADMD instances.
+	MaxDaemons int `json:"maxDaemons,omitempty"`
+	// Max number of nginx processes to support.
+	MaxWorkers int `json:"maxWorkers,omitempty"`
+	// RAM memory size in MB.
+	Memory int `json:"memory,omitempty"`
+}
+
+// Pod defines the customization of the Ingress Controller pods.
+type Pod struct {
+	// The compute resources of the Ingress Controller container.
+	// +kubebuilder:validation:Optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// The labels of the nodes the Ingress Controller pods can be scheduled on.
+	// +kubebuilder:validation:Optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// The tolerations of the Ingress Controller pods.
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// The scheduling constraints of the Ingress Controller pods.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// How the Ingress Controller pods are spread across the topology domains.
+	// +kubebuilder:validation:Optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// The PriorityClass of the Ingress Controller pods.
+	// +kubebuilder:validation:Optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Specifies extra labels of the pods. The app label is set by the Operator and cannot be overridden.
+	// +kubebuilder:validation:Optional
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// Specifies extra annotations of the pods.
+	// +kubebuilder:validation:Optional
+	ExtraAnnotations map[string]string `json:"extraAnnotations,omitempty"`
+}
+
+// OperatorCA defines the certificates issued by the certificate authority (CA) managed by the Operator.
+// The CA certificate is published in a ConfigMap in the namespace of the Operator, so clients can trust the issued certificates.
+type OperatorCA struct {
+	// Issue the certificate of the default server when defaultSecret is not set, instead of generating a self-signed certificate.
+	// The certificate is configured with defaultCertificate.
+	// +kubebuilder:validation:Optional
+	DefaultServer bool `json:"defaultServer,omitempty"`
+	// Issue a wildcard certificate for the subdomains of the domain and use it as the wildcard TLS Secret.
+	// For example, example.com issues a certificate for *.example.com. Cannot be used together with wildcardTLS.
+	// +kubebuilder:validation:Optional
+	WildcardDomain string `json:"wildcardDomain,omitempty"`
+	// Issue the certificate of the Prometheus endpoint when prometheus is enabled. Cannot be used together with prometheus.secret.
+	// +kubebuilder:validation:Optional
+	Prometheus bool `json:"prometheus,omitempty"`
+}
+
+// CertManager defines the certificates issued by cert-manager. The Operator creates a cert-manager Certificate for each of them,
+// and waits for the Certificates to be ready before it deploys the Ingress Controller.
+type CertManager struct {
+	// The cert-manager Issuer or ClusterIssuer that issues the certificates.
+	IssuerRef CertManagerIssuerReference `json:"issuerRef"`
+	// Issue the certificate of the default server when defaultSecret is not set.
+	// The certificate is configured with defaultCertificate.
+	// +kubebuilder:validation:Optional
+	DefaultServer bool `json:"defaultServer,omitempty"`
+	// Issue a wildcard certificate for the subdomains of the domain and use it as the wildcard TLS Secret.
+	// For example, example.com issues a certificate for *.example.com. Cannot be used together with wildcardTLS.
+	// +kubebuilder:validation:Optional
+	WildcardDomain string `json:"wildcardDomain,omitempty"`
+	// Issue the certificate of the Prometheus endpoint when prometheus is enabled. Cannot be used together with prometheus.secret.
+	// +kubebuilder:validation:Optional
+	Prometheus bool `json:"prometheus,omitempty"`
+}
+
+// CertManagerIssuerReference is a reference to a cert-manager Issuer or ClusterIssuer.
+type CertManagerIssuerReference struct {
+	// The name of the Issuer or ClusterIssuer. An Issuer must be in the namespace of the NginxIngressController.
+	Name string `json:"name"`
+	// The kind of the issuer, Issuer or ClusterIssuer. Default is Issuer.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+	// The API group of the issuer. Default is cert-manager.io. Set it to use an external issuer.
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+}
+
+// DefaultCertificate defines the self-signed certificate generated for the default server.
+// The certificate is regenerated when any of its fields change.
+type DefaultCertificate struct {
+	// The common name of the certificate. Default is example.com.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=64
+	CommonName string `json:"commonName,omitempty"`
+	// The DNS names of the subject alternative names of the certificate. Default is the common name.
+	// +kubebuilder:validation:Optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// The IP addresses of the subject alternative names of the certificate.
+	// +kubebuilder:validation:Optional
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+	// The algorithm and size of the private key: RSA-2048, RSA-4096, ECDSA-P256, ECDSA-P384 or Ed25519.
+	// Default is RSA-2048.
+	// +kubebuilder:validation:Optional
+	KeyAlgorithm KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+	// The validity of the certificate. Must be greater than renewBefore.
+	// Default is 8760h (365 days).
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before the expiry the certificate is regenerated.
+	// Default is 720h (30 days).
+	// +kubebuilder:validation:Optional
+	// +nullable
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// WorkloadMigration defines the migration between the deployment and daemonset types.
+type WorkloadMigration struct {
+	// Wait for the new workload to become available before removing the old one. Otherwise, the old workload is removed
+	// as soon as the new one is created. Default is true.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Enable *bool `json:"enable,omitempty"`
+	// The time the new workload has to become available. If the timeout expires, the migration is rolled back:
+	// the new workload is removed and the old one is kept until the spec of the NginxIngressController changes.
+	// Default is 10m.
+	// +kubebuilder:validation:Optional
+	// +nullable
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Service defines the Service for the Ingress Controller.
+type Service struct {
+	// Specifies extra labels of the service.
+	// +kubebuilder:validation:Optional
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// Specifies extra annotations of the service.
+	// +kubebuilder:validation:Optional
+	ExtraAnnotations map[string]string `json:"extraAnnotations,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NginxIngressController{}, &NginxIngressControllerList{})
+}
@@ -0,0 +1,419 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var nginxingresscontrollerlog = logf.Log.WithName("nginxingresscontroller-resource")
+
+// SetupWebhookWithManager registers the webhooks of the NginxIngressController with the manager.
+func (r *NginxIngressController) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-k8s-nginx-org-v1beta1-nginxingresscontroller,mutating=true,failurePolicy=fail,sideEffects=None,groups=k8s.nginx.org,resources=nginxingresscontrollers,verbs=create;update,versions=v1beta1,name=mnginxingresscontroller.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &NginxIngressController{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *NginxIngressController) Default() {
+	nginxingresscontrollerlog.Info("default", "name", r.Name)
+
+	defaultNginxIngressControllerSpec(&r.Spec)
+}
+
+// defaultNginxIngressControllerSpec sets the values the Operator uses for the fields that are not set,
+// so the stored NginxIngressController shows the configuration of the running Ingress Controller.
+func defaultNginxIngressControllerSpec(spec *NginxIngressControllerSpec) {
+	if spec.IngressClass == "" {
+		spec.IngressClass = DefaultIngressClass
+	}
+
+	// Replicas are not defaulted, so the replicas of the Deployment can be managed by a HorizontalPodAutoscaler.
+
+	if spec.EnableLeaderElection == nil {
+		enable := true
+		spec.EnableLeaderElection = &enable
+	}
+
+	if spec.EnableCRDs == nil {
+		enable := true
+		spec.EnableCRDs = &enable
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-k8s-nginx-org-v1beta1-nginxingresscontroller,mutating=false,failurePolicy=fail,sideEffects=None,groups=k8s.nginx.org,resources=nginxingresscontrollers,verbs=create;update,versions=v1beta1,name=vnginxingresscontroller.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &NginxIngressController{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *NginxIngressController) ValidateCreate() error {
+	nginxingresscontrollerlog.Info("validate create", "name", r.Name)
+
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Only the errors that the old object did not already have are rejected, so the NginxIngressControllers created before a
+// rule was added can still be updated, and a NginxIngressController being deleted can always have its finalizer removed.
+func (r *NginxIngressController) ValidateUpdate(old runtime.Object) error {
+	nginxingresscontrollerlog.Info("validate update", "name", r.Name)
+
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+	oldController, ok := old.(*NginxIngressController)
+	if !ok {
+		return r.validate()
+	}
+
+	fieldPath := field.NewPath("spec")
+	allErrs := newErrors(validateNginxIngressControllerSpec(&r.Spec, fieldPath), validateNginxIngressControllerSpec(&oldController.Spec, fieldPath))
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("NginxIngressController").GroupKind(), r.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *NginxIngressController) ValidateDelete() error {
+	return nil
+}
+
+func (r *NginxIngressController) validate() error {
+	allErrs := validateNginxIngressControllerSpec(&r.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("NginxIngressController").GroupKind(), r.Name, allErrs)
+}
+
+// newErrors returns the errors that are not in the old errors.
+func newErrors(allErrs field.ErrorList, oldErrs field.ErrorList) field.ErrorList {
+	var result field.ErrorList
+	for _, err := range allErrs {
+		found := false
+		for _, oldErr := range oldErrs {
+			if err.Type == oldErr.Type && err.Field == oldErr.Field && reflect.DeepEqual(err.BadValue, oldErr.BadValue) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// validateNginxIngressControllerSpec validates the combinations of fields that the Ingress Controller does not support
+// and that would otherwise be silently ignored when generating the arguments of the Ingress Controller pods.
+func validateNginxIngressControllerSpec(spec *NginxIngressControllerSpec, fieldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Earlier versions of the Operator ignored replicas with daemonset. The value is part of the error, so the existing
+	// NginxIngressControllers are only rejected when replicas or type changes.
+	if spec.Replicas != nil && spec.Type == WorkloadKindDaemonSet {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("replicas"), *spec.Replicas, "replicas can only be set when type is deployment"))
+	}
+
+	allErrs = append(allErrs, validateObjectReference(spec.DefaultSecret, fieldPath.Child("defaultSecret"))...)
+	allErrs = append(allErrs, validateObjectReference(spec.WildcardTLS, fieldPath.Child("wildcardTLS"))...)
+	allErrs = append(allErrs, validateObjectReference(spec.GlobalConfiguration, fieldPath.Child("globalConfiguration"))...)
+
+	if spec.IngressClassParameters != nil {
+		gc := spec.IngressClassParameters.GlobalConfiguration
+		allErrs = append(allErrs, validateObjectReference(gc, fieldPath.Child("ingressClassParameters", "globalConfiguration"))...)
+		// The Ingress Controller uses a single GlobalConfiguration, so the class cannot advertise another one.
+		if gc != nil && spec.GlobalConfiguration != nil && *gc != *spec.GlobalConfiguration {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("ingressClassParameters", "globalConfiguration"),
+				*gc, "must be the same as globalConfiguration when both are set"))
+		}
+	}
+
+	if spec.Prometheus != nil {
+		allErrs = append(allErrs, validateObjectReference(spec.Prometheus.Secret, fieldPath.Child("prometheus", "secret"))...)
+	}
+
+	if spec.NginxStatus != nil {
+		allErrs = append(allErrs, validateAllowCidrs(spec.NginxStatus.AllowCidrs, fieldPath.Child("nginxStatus", "allowCidrs"))...)
+	}
+
+	if !spec.NginxPlus {
+		if spec.AppProtect != nil && spec.AppProtect.Enable {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("appProtect", "enable"), "App Protect requires nginxPlus set to true"))
+		}
+		if spec.AppProtectDos != nil && spec.AppProtectDos.Enable {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("appProtectDos", "enable"), "App Protect DoS requires nginxPlus set to true"))
+		}
+	}
+
+	if spec.EnableCRDs != nil && !*spec.EnableCRDs {
+		msg := "requires enableCRDs set to true"
+		if spec.EnableSnippets {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("enableSnippets"), msg))
+		}
+		if spec.EnablePreviewPolicies {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("enablePreviewPolicies"), msg))
+		}
+		if spec.EnableTLSPassthrough {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("enableTLSPassthrough"), msg))
+		}
+		if spec.GlobalConfiguration != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("globalConfiguration"), msg))
+		}
+		if spec.IngressClassParameters != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("ingressClassParameters"), msg))
+		}
+		if spec.AppProtect != nil && spec.AppProtect.Enable {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("appProtect", "enable"), msg))
+		}
+		if spec.AppProtectDos != nil && spec.AppProtectDos.Enable {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("appProtectDos", "enable"), msg))
+		}
+	}
+
+	if spec.ReadyStatus != nil {
+		allErrs = append(allErrs, validateReadyStatus(spec, fieldPath.Child("readyStatus"))...)
+	}
+
+	if spec.Pod != nil {
+		allErrs = append(allErrs, validatePod(spec.Pod, fieldPath.Child("pod"))...)
+	}
+
+	if spec.DefaultCertificate != nil {
+		allErrs = append(allErrs, validateDefaultCertificate(spec.DefaultCertificate, fieldPath.Child("defaultCertificate"))...)
+	}
+
+	if spec.OperatorCA != nil {
+		allErrs = append(allErrs, validateOperatorCA(spec, fieldPath.Child("operatorCA"))...)
+	}
+
+	if spec.CertManager != nil {
+		allErrs = append(allErrs, validateCertManager(spec, fieldPath.Child("certManager"))...)
+	}
+
+	if spec.WorkloadMigration != nil && spec.WorkloadMigration.Timeout != nil && spec.WorkloadMigration.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("workloadMigration", "timeout"), spec.WorkloadMigration.Timeout.Duration.String(), "must be greater than zero"))
+	}
+
+	if spec.ReportIngressStatus != nil && spec.ReportIngressStatus.IngressLink != "" {
+		ingressLinkPath := fieldPath.Child("reportIngressStatus", "ingressLink")
+		if spec.ReportIngressStatus.ExternalService != "" {
+			allErrs = append(allErrs, field.Forbidden(ingressLinkPath, "ingressLink cannot be set together with externalService"))
+		}
+		if !spec.ReportIngressStatus.Enable {
+			allErrs = append(allErrs, field.Forbidden(ingressLinkPath, "requires reportIngressStatus.enable set to true"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateReadyStatus validates the probes and that the port of the readiness endpoint is not used by
+// the NGINX status or the Prometheus metrics.
+func validateReadyStatus(spec *NginxIngressControllerSpec, fieldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	rs := spec.ReadyStatus
+	if rs.Enable != nil && !*rs.Enable {
+		return nil
+	}
+
+	allErrs = append(allErrs, validateSuccessThreshold(rs.LivenessProbe, fieldPath.Child("livenessProbe"))...)
+	allErrs = append(allErrs, validateSuccessThreshold(rs.StartupProbe, fieldPath.Child("startupProbe"))...)
+
+	if rs.Port == nil {
+		return allErrs
+	}
+
+	port := *rs.Port
+	if spec.NginxStatus != nil && spec.NginxStatus.Enable && spec.NginxStatus.Port != nil && *spec.NginxStatus.Port == port {
+		allErrs = append(allErrs, field.Duplicate(fieldPath.Child("port"), port))
+	}
+	if spec.Prometheus != nil && spec.Prometheus.Enable && spec.Prometheus.Port != nil && *spec.Prometheus.Port == port {
+		allErrs = append(allErrs, field.Duplicate(fieldPath.Child("port"), port))
+	}
+
+	return allErrs
+}
+
+// validateSuccessThreshold validates that the success threshold of a liveness or startup probe is 1, as required by Kubernetes.
+func validateSuccessThreshold(probe *Probe, fieldPath *field.Path) field.ErrorList {
+	if probe == nil || probe.SuccessThreshold == nil || *probe.SuccessThreshold == 1 {
+		return nil
+	}
+
+	return field.ErrorList{field.Invalid(fieldPath.Child("successThreshold"), *probe.SuccessThreshold, "must be 1")}
+}
+
+// validatePod validates the extra labels and annotations of the pods.
+// The rest of the fields are validated by the API server when the Deployment or DaemonSet is created.
+func validatePod(pod *Pod, fieldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	labelsPath := fieldPath.Child("extraLabels")
+	allErrs = append(allErrs, metav1validation.ValidateLabels(pod.ExtraLabels, labelsPath)...)
+	if _, ok := pod.ExtraLabels["app"]; ok {
+		allErrs = append(allErrs, field.Forbidden(labelsPath.Key("app"), "the app label is set by the Operator"))
+	}
+
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(pod.ExtraAnnotations, fieldPath.Child("extraAnnotations"))...)
+
+	return allErrs
+}
+
+// validateDefaultCertificate validates the subject alternative names of the default certificate and that the certificate is valid
+// for longer than its renewal window.
+func validateDefaultCertificate(dc *DefaultCertificate, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, name := range dc.DNSNames {
+		msgs := validation.IsDNS1123Subdomain(name)
+		if strings.HasPrefix(name, "*.") {
+			msgs = validation.IsWildcardDNS1123Subdomain(name)
+		}
+		for _, msg := range msgs {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("dnsNames").Index(i), name, msg))
+		}
+	}
+
+	for i, ip := range dc.IPAddresses {
+		if net.ParseIP(ip) == nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("ipAddresses").Index(i), ip, "must be an IP address"))
+		}
+	}
+
+	duration := DefaultCertificateDuration
+	if dc.Duration != nil {
+		duration = dc.Duration.Duration
+	}
+	renewBefore := DefaultCertificateRenewBefore
+	if dc.RenewBefore != nil {
+		renewBefore = dc.RenewBefore.Duration
+		if renewBefore <= 0 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("renewBefore"), renewBefore.String(), "must be greater than zero"))
+		}
+	}
+	if duration <= renewBefore {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("duration"), duration.String(), fmt.Sprintf("must be greater than renewBefore (%v)", renewBefore)))
+	}
+
+	return allErrs
+}
+
+// validateOperatorCA validates the certificates issued by the Operator CA.
+func validateOperatorCA(spec *NginxIngressControllerSpec, fieldPath *field.Path) field.ErrorList {
+	ca := spec.OperatorCA
+	return validateIssuedCertificates(spec, ca.DefaultServer, ca.WildcardDomain, ca.Prometheus, fieldPath)
+}
+
+// validateCertManager validates the issuer and the certificates issued by cert-manager, and that the Operator CA is not used
+// at the same time.
+func validateCertManager(spec *NginxIngressControllerSpec, fieldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	cm := spec.CertManager
+	if spec.OperatorCA != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "cannot be set together with operatorCA"))
+	}
+	if cm.IssuerRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("issuerRef", "name"), ""))
+	}
+
+	return append(allErrs, validateIssuedCertificates(spec, cm.DefaultServer, cm.WildcardDomain, cm.Prometheus, fieldPath)...)
+}
+
+// validateIssuedCertificates validates the wildcard domain and that the issued certificates don't replace Secrets set in the spec.
+func validateIssuedCertificates(spec *NginxIngressControllerSpec, defaultServer bool, wildcardDomain string, prometheus bool, fieldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if defaultServer && spec.DefaultSecret != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("defaultServer"), "cannot be set together with defaultSecret"))
+	}
+	if wildcardDomain != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(wildcardDomain) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("wildcardDomain"), wildcardDomain, msg))
+		}
+		if spec.WildcardTLS != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("wildcardDomain"), "cannot be set together with wildcardTLS"))
+		}
+	}
+	if prometheus && spec.Prometheus != nil && spec.Prometheus.Secret != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("prometheus"), "cannot be set together with prometheus.secret"))
+	}
+
+	return allErrs
+}
+
+// validateObjectReference validates a reference to a resource. An empty reference is valid.
+func validateObjectReference(ref *ObjectReference, fieldPath *field.Path) field.ErrorList {
+	if ref == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if ref.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(ref.Namespace) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("namespace"), ref.Namespace, msg))
+		}
+	}
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("name"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(ref.Name) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("name"), ref.Name, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateAllowCidrs validates that every entry is an IP address or a CIDR block.
+func validateAllowCidrs(cidrs []string, fieldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, cidr := range cidrs {
+		if net.ParseIP(cidr) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), cidr, "must be an IP address or a CIDR block"))
+		}
+	}
+
+	return allErrs
+}
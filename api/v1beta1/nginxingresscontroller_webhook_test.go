@@ -0,0 +1,444 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateNginxIngressControllerSpec(t *testing.T) {
+	enable := true
+	disable := false
+	replicas := int32(2)
+	port := uint16(8080)
+
+	tests := []struct {
+		spec     NginxIngressControllerSpec
+		expected []string
+		msg      string
+	}{
+		{
+			spec: NginxIngressControllerSpec{
+				Type:                WorkloadKindDeployment,
+				Replicas:            &replicas,
+				DefaultSecret:       &ObjectReference{Namespace: "my-namespace", Name: "my-secret"},
+				WildcardTLS:         &ObjectReference{Name: "my-wildcard"},
+				GlobalConfiguration: &ObjectReference{Namespace: "my-namespace", Name: "my-configuration"},
+				EnableSnippets:      true,
+				NginxStatus: &NginxStatus{
+					Enable:     true,
+					AllowCidrs: []string{"127.0.0.1", "10.0.0.0/8"},
+				},
+				Prometheus: &Prometheus{
+					Enable: true,
+					Secret: &ObjectReference{Namespace: "my-namespace", Name: "my-prometheus"},
+				},
+				ReportIngressStatus: &ReportIngressStatus{
+					Enable:      true,
+					IngressLink: "my-ingresslink",
+				},
+			},
+			msg: "valid spec",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				NginxPlus: true,
+				AppProtect: &AppProtect{
+					Enable: true,
+				},
+				AppProtectDos: &AppProtectDos{
+					Enable: true,
+				},
+				EnableCRDs: &enable,
+			},
+			msg: "valid app protect with nginx plus",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				Type:     WorkloadKindDaemonSet,
+				Replicas: &replicas,
+			},
+			expected: []string{"spec.replicas"},
+			msg:      "replicas with daemonset",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				EnableCRDs:            &disable,
+				EnableSnippets:        true,
+				EnablePreviewPolicies: true,
+				EnableTLSPassthrough:  true,
+				GlobalConfiguration:   &ObjectReference{Namespace: "my-namespace", Name: "my-configuration"},
+				IngressClassParameters: &IngressClassParametersSpec{
+					GlobalConfiguration: &ObjectReference{Namespace: "my-namespace", Name: "my-configuration"},
+				},
+			},
+			expected: []string{"spec.enableSnippets", "spec.enablePreviewPolicies", "spec.enableTLSPassthrough", "spec.globalConfiguration", "spec.ingressClassParameters"},
+			msg:      "features that require custom resources with enableCRDs false",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				GlobalConfiguration: &ObjectReference{Namespace: "my-namespace", Name: "my-configuration"},
+				IngressClassParameters: &IngressClassParametersSpec{
+					GlobalConfiguration: &ObjectReference{Namespace: "my-namespace", Name: "other-configuration"},
+				},
+			},
+			expected: []string{"spec.ingressClassParameters.globalConfiguration"},
+			msg:      "ingressClassParameters with a different globalConfiguration",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				AppProtect: &AppProtect{
+					Enable: true,
+				},
+				AppProtectDos: &AppProtectDos{
+					Enable: true,
+				},
+			},
+			expected: []string{"spec.appProtect.enable", "spec.appProtectDos.enable"},
+			msg:      "app protect without nginx plus",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				DefaultSecret:       &ObjectReference{Namespace: "my-namespace", Name: "My_Secret"},
+				WildcardTLS:         &ObjectReference{Namespace: "my-namespace/extra", Name: "my-wildcard"},
+				GlobalConfiguration: &ObjectReference{Namespace: "My_Namespace", Name: "my-configuration"},
+				IngressClassParameters: &IngressClassParametersSpec{
+					GlobalConfiguration: &ObjectReference{Namespace: "My_Namespace", Name: "my-configuration"},
+				},
+				Prometheus: &Prometheus{
+					Enable: true,
+					Secret: &ObjectReference{Namespace: "my-namespace"},
+				},
+			},
+			expected: []string{"spec.defaultSecret.name", "spec.wildcardTLS.namespace", "spec.globalConfiguration.namespace",
+				"spec.ingressClassParameters.globalConfiguration.namespace", "spec.prometheus.secret.name"},
+			msg: "malformed references",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				NginxStatus: &NginxStatus{
+					Enable:     true,
+					AllowCidrs: []string{"127.0.0.1", "10.0.0.0/33", "localhost"},
+				},
+			},
+			expected: []string{"spec.nginxStatus.allowCidrs[1]", "spec.nginxStatus.allowCidrs[2]"},
+			msg:      "invalid allowCidrs",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				Pod: &Pod{
+					ExtraLabels:      map[string]string{"app": "my-app", "team": "ingress"},
+					ExtraAnnotations: map[string]string{"invalid key": "value"},
+				},
+			},
+			expected: []string{"spec.pod.extraLabels[app]", "spec.pod.extraAnnotations"},
+			msg:      "app label and invalid annotation in pod",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				NginxStatus: &NginxStatus{
+					Enable: true,
+					Port:   &port,
+				},
+				ReadyStatus: &ReadyStatus{
+					Port: &port,
+					ReadinessProbe: &Probe{
+						SuccessThreshold: &replicas,
+					},
+					LivenessProbe: &Probe{
+						SuccessThreshold: &replicas,
+					},
+				},
+			},
+			expected: []string{"spec.readyStatus.livenessProbe.successThreshold", "spec.readyStatus.port"},
+			msg:      "ready status with invalid liveness probe and duplicate port",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				ReportIngressStatus: &ReportIngressStatus{
+					Enable:          true,
+					ExternalService: "my-service",
+					IngressLink:     "my-ingresslink",
+				},
+			},
+			expected: []string{"spec.reportIngressStatus.ingressLink"},
+			msg:      "ingressLink with externalService",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				ReportIngressStatus: &ReportIngressStatus{
+					Enable:      false,
+					IngressLink: "my-ingresslink",
+				},
+			},
+			expected: []string{"spec.reportIngressStatus.ingressLink"},
+			msg:      "ingressLink with reportIngressStatus disabled",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				WorkloadMigration: &WorkloadMigration{
+					Timeout: &metav1.Duration{Duration: 0},
+				},
+			},
+			expected: []string{"spec.workloadMigration.timeout"},
+			msg:      "workload migration with zero timeout",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				DefaultCertificate: &DefaultCertificate{
+					RenewBefore: &metav1.Duration{Duration: -time.Hour},
+				},
+			},
+			expected: []string{"spec.defaultCertificate.renewBefore"},
+			msg:      "default certificate with negative renewBefore",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				DefaultCertificate: &DefaultCertificate{
+					DNSNames:    []string{"ingress.example.com", "*.example.com", "Invalid_Name"},
+					IPAddresses: []string{"10.0.0.1", "10.0.0"},
+				},
+			},
+			expected: []string{"spec.defaultCertificate.dnsNames[2]", "spec.defaultCertificate.ipAddresses[1]"},
+			msg:      "default certificate with invalid subject alternative names",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				DefaultCertificate: &DefaultCertificate{
+					Duration: &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+			expected: []string{"spec.defaultCertificate.duration"},
+			msg:      "default certificate with duration shorter than the default renewBefore",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				DefaultSecret: &ObjectReference{Name: "my-secret"},
+				WildcardTLS:   &ObjectReference{Name: "my-wildcard-secret"},
+				Prometheus:    &Prometheus{Enable: true, Secret: &ObjectReference{Name: "my-prometheus-secret"}},
+				OperatorCA: &OperatorCA{
+					DefaultServer:  true,
+					WildcardDomain: "example.com",
+					Prometheus:     true,
+				},
+			},
+			expected: []string{"spec.operatorCA.defaultServer", "spec.operatorCA.wildcardDomain", "spec.operatorCA.prometheus"},
+			msg:      "operator CA with secrets set in the spec",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				OperatorCA: &OperatorCA{WildcardDomain: "*.example.com"},
+			},
+			expected: []string{"spec.operatorCA.wildcardDomain"},
+			msg:      "operator CA with invalid wildcard domain",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				WildcardTLS: &ObjectReference{Name: "my-wildcard-secret"},
+				OperatorCA:  &OperatorCA{DefaultServer: true},
+				CertManager: &CertManager{WildcardDomain: "example.com"},
+			},
+			expected: []string{"spec.certManager", "spec.certManager.issuerRef.name", "spec.certManager.wildcardDomain"},
+			msg:      "cert-manager with operator CA, without issuer and with wildcardTLS",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				CertManager: &CertManager{
+					IssuerRef:     CertManagerIssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+					DefaultServer: true,
+				},
+			},
+			expected: nil,
+			msg:      "cert-manager with cluster issuer",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateNginxIngressControllerSpec(&test.spec, field.NewPath("spec"))
+
+		var result []string
+		for _, err := range allErrs {
+			result = append(result, err.Field)
+		}
+
+		if len(result) != len(test.expected) {
+			t.Errorf("validateNginxIngressControllerSpec() returned errors for %v but expected %v for the case of %v", result, test.expected, test.msg)
+			continue
+		}
+		for i := range result {
+			if result[i] != test.expected[i] {
+				t.Errorf("validateNginxIngressControllerSpec() returned errors for %v but expected %v for the case of %v", result, test.expected, test.msg)
+				break
+			}
+		}
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	instance := &NginxIngressController{
+		Spec: NginxIngressControllerSpec{
+			Type:          WorkloadKindDeployment,
+			DefaultSecret: &ObjectReference{Name: "Invalid"},
+		},
+	}
+
+	err := instance.ValidateCreate()
+	if err == nil {
+		t.Fatalf("ValidateCreate() returned no error for an invalid spec")
+	}
+
+	instance.Spec.DefaultSecret = &ObjectReference{Namespace: "my-namespace", Name: "my-secret"}
+	if err := instance.ValidateCreate(); err != nil {
+		t.Errorf("ValidateCreate() returned unexpected error %v", err)
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	deleted := metav1.Now()
+	invalid := NginxIngressControllerSpec{
+		Type:          WorkloadKindDeployment,
+		DefaultSecret: &ObjectReference{Name: "Invalid"},
+	}
+	withWildcard := invalid
+	withWildcard.WildcardTLS = &ObjectReference{Name: "Invalid"}
+	replicas := int32(1)
+	moreReplicas := int32(2)
+
+	tests := []struct {
+		old           *NginxIngressController
+		instance      *NginxIngressController
+		expectedError bool
+		msg           string
+	}{
+		{
+			old: &NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{Finalizers: []string{"nginxingresscontroller.k8s.nginx.org/finalizer"}},
+				Spec:       invalid,
+			},
+			instance:      &NginxIngressController{Spec: invalid},
+			expectedError: false,
+			msg:           "finalizer removed from an invalid NginxIngressController",
+		},
+		{
+			old:           &NginxIngressController{Spec: invalid},
+			instance:      &NginxIngressController{Spec: withWildcard},
+			expectedError: true,
+			msg:           "new error added to an invalid NginxIngressController",
+		},
+		{
+			old:           &NginxIngressController{Spec: invalid},
+			instance:      &NginxIngressController{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}, Spec: withWildcard},
+			expectedError: false,
+			msg:           "NginxIngressController being deleted",
+		},
+		{
+			old:           &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDeployment}},
+			instance:      &NginxIngressController{Spec: invalid},
+			expectedError: true,
+			msg:           "valid NginxIngressController made invalid",
+		},
+		{
+			old:           &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDaemonSet, Replicas: &replicas}},
+			instance:      &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDaemonSet, Replicas: &replicas, NginxDebug: true}},
+			expectedError: false,
+			msg:           "existing replicas with daemonset",
+		},
+		{
+			old:           &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDaemonSet, Replicas: &replicas}},
+			instance:      &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDaemonSet, Replicas: &moreReplicas}},
+			expectedError: true,
+			msg:           "replicas changed with daemonset",
+		},
+		{
+			old:           &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDeployment, Replicas: &replicas}},
+			instance:      &NginxIngressController{Spec: NginxIngressControllerSpec{Type: WorkloadKindDaemonSet, Replicas: &replicas}},
+			expectedError: true,
+			msg:           "type changed to daemonset with replicas",
+		},
+	}
+
+	for _, test := range tests {
+		err := test.instance.ValidateUpdate(test.old)
+		if (err != nil) != test.expectedError {
+			t.Errorf("ValidateUpdate() returned %v but expected error %v for the case of %v", err, test.expectedError, test.msg)
+		}
+	}
+}
+
+func TestDefaultNginxIngressControllerSpec(t *testing.T) {
+	enable := true
+	disable := false
+	replicas := int32(3)
+
+	tests := []struct {
+		spec     NginxIngressControllerSpec
+		expected NginxIngressControllerSpec
+		msg      string
+	}{
+		{
+			spec: NginxIngressControllerSpec{
+				Type: WorkloadKindDeployment,
+			},
+			expected: NginxIngressControllerSpec{
+				Type:                 WorkloadKindDeployment,
+				IngressClass:         "nginx",
+				EnableLeaderElection: &enable,
+				EnableCRDs:           &enable,
+			},
+			msg: "empty deployment",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				Type: WorkloadKindDaemonSet,
+			},
+			expected: NginxIngressControllerSpec{
+				Type:                 WorkloadKindDaemonSet,
+				IngressClass:         "nginx",
+				EnableLeaderElection: &enable,
+				EnableCRDs:           &enable,
+			},
+			msg: "empty daemonset",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				Type:                 WorkloadKindDeployment,
+				IngressClass:         "my-class",
+				Replicas:             &replicas,
+				EnableLeaderElection: &disable,
+				EnableCRDs:           &disable,
+			},
+			expected: NginxIngressControllerSpec{
+				Type:                 WorkloadKindDeployment,
+				IngressClass:         "my-class",
+				Replicas:             &replicas,
+				EnableLeaderElection: &disable,
+				EnableCRDs:           &disable,
+			},
+			msg: "set fields are not overwritten",
+		},
+		{
+			spec: NginxIngressControllerSpec{
+				Type:     WorkloadKindDaemonSet,
+				Replicas: &replicas,
+			},
+			expected: NginxIngressControllerSpec{
+				Type:                 WorkloadKindDaemonSet,
+				IngressClass:         "nginx",
+				Replicas:             &replicas,
+				EnableLeaderElection: &enable,
+				EnableCRDs:           &enable,
+			},
+			msg: "explicit replicas kept for validation with daemonset",
+		},
+	}
+
+	for _, test := range tests {
+		defaultNginxIngressControllerSpec(&test.spec)
+		if !reflect.DeepEqual(test.spec, test.expected) {
+			t.Errorf("defaultNginxIngressControllerSpec() returned %+v but expected %+v for the case of %v", test.spec, test.expected, test.msg)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package kic embeds the CustomResourceDefinitions of the NGINX Ingress Controller that are installed by the Operator.
+package kic
+
+import "embed"
+
+// CRDs contains the manifests of the CRDs of the NGINX Ingress Controller.
+//
+//go:embed *.yaml
+var CRDs embed.FS
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// fieldManager is the field manager used by the Operator to apply the resources of a NginxIngressController.
+const fieldManager = "nginx-ingress-operator"
+
+// legacyFieldManagers are the field managers recorded by the API server for the create and update requests sent by earlier
+// versions of the Operator, before the resources were reconciled with server-side apply. The client of those versions used the
+// name of the binary as the field manager: manager in the Operator image, and main when run with make run or go run.
+// Other clients can use the same names, so the entries are only trusted on the objects controlled by a NginxIngressController.
+var legacyFieldManagers = map[string]bool{"manager": true, "main": true}
+
+// apply sends the desired state of the object to the API server with server-side apply. The Operator takes the ownership
+// of every field set in the object, forcing the conflicts with other managers, so any change made out of band to those
+// fields is reverted. Fields not set in the object (like the replicas of a Deployment scaled by a HorizontalPodAutoscaler)
+// are left to their managers. The object is updated with the state returned by the API server, and an event is recorded
+// on the NginxIngressController when the object is created or changed.
+func (r *NginxIngressControllerReconciler) apply(ctx context.Context, instance *k8sv1beta1.NginxIngressController, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", obj)
+	}
+	err = r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if err != nil && errors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return err
+	} else if err := r.releaseLegacyFields(ctx, instance, existing); err != nil {
+		return err
+	}
+
+	err = r.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		r.recordCreated(instance, obj)
+	} else if existing.GetResourceVersion() != obj.GetResourceVersion() {
+		r.recordUpdated(instance, obj)
+	}
+
+	return nil
+}
+
+// releaseLegacyFields removes the managed fields entries of the legacy field managers from the existing object when it is
+// controlled by the NginxIngressController. Otherwise, the fields set by earlier versions of the Operator would stay owned by
+// the legacy manager and would not be removed from the object when they are no longer part of the applied state.
+func (r *NginxIngressControllerReconciler) releaseLegacyFields(ctx context.Context, instance *k8sv1beta1.NginxIngressController, existing client.Object) error {
+	if !metav1.IsControlledBy(existing, instance) {
+		return nil
+	}
+	managedFields, found := withoutLegacyFieldManager(existing.GetManagedFields())
+	if !found {
+		return nil
+	}
+
+	patch := client.MergeFrom(existing.DeepCopyObject().(client.Object))
+	existing.SetManagedFields(managedFields)
+	return r.Patch(ctx, existing, patch)
+}
+
+// withoutLegacyFieldManager returns the managed fields entries that don't belong to the update requests of the legacy
+// field managers, and whether any entry was removed.
+// An empty list leaves the managed fields unchanged in the API server, so a single empty entry is returned instead to clear them.
+func withoutLegacyFieldManager(entries []metav1.ManagedFieldsEntry) ([]metav1.ManagedFieldsEntry, bool) {
+	var result []metav1.ManagedFieldsEntry
+	found := false
+	for _, e := range entries {
+		if legacyFieldManagers[e.Manager] && e.Operation == metav1.ManagedFieldsOperationUpdate {
+			found = true
+			continue
+		}
+		result = append(result, e)
+	}
+
+	if len(result) == 0 {
+		result = []metav1.ManagedFieldsEntry{{}}
+	}
+
+	return result, found
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestWithoutLegacyFieldManager(t *testing.T) {
+	operator := metav1.ManagedFieldsEntry{Manager: fieldManager, Operation: metav1.ManagedFieldsOperationApply}
+	legacy := metav1.ManagedFieldsEntry{Manager: "manager", Operation: metav1.ManagedFieldsOperationUpdate}
+	legacyMain := metav1.ManagedFieldsEntry{Manager: "main", Operation: metav1.ManagedFieldsOperationUpdate}
+	legacyApply := metav1.ManagedFieldsEntry{Manager: "manager", Operation: metav1.ManagedFieldsOperationApply}
+	hpa := metav1.ManagedFieldsEntry{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "scale"}
+
+	tests := []struct {
+		entries  []metav1.ManagedFieldsEntry
+		expected []metav1.ManagedFieldsEntry
+		found    bool
+		msg      string
+	}{
+		{
+			entries:  []metav1.ManagedFieldsEntry{operator, hpa},
+			expected: []metav1.ManagedFieldsEntry{operator, hpa},
+			found:    false,
+			msg:      "no legacy manager",
+		},
+		{
+			entries:  []metav1.ManagedFieldsEntry{legacy, hpa},
+			expected: []metav1.ManagedFieldsEntry{hpa},
+			found:    true,
+			msg:      "legacy manager removed",
+		},
+		{
+			entries:  []metav1.ManagedFieldsEntry{legacyMain, operator},
+			expected: []metav1.ManagedFieldsEntry{operator},
+			found:    true,
+			msg:      "legacy manager of make run removed",
+		},
+		{
+			entries:  []metav1.ManagedFieldsEntry{legacyApply},
+			expected: []metav1.ManagedFieldsEntry{legacyApply},
+			found:    false,
+			msg:      "apply operation of the legacy manager kept",
+		},
+		{
+			entries:  []metav1.ManagedFieldsEntry{legacy},
+			expected: []metav1.ManagedFieldsEntry{{}},
+			found:    true,
+			msg:      "only legacy manager",
+		},
+	}
+
+	for _, test := range tests {
+		result, found := withoutLegacyFieldManager(test.entries)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("withoutLegacyFieldManager() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+		if found != test.found {
+			t.Errorf("withoutLegacyFieldManager() returned %v but expected %v for the case of %v", found, test.found, test.msg)
+		}
+	}
+}
+
+func TestReleaseLegacyFields(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default", UID: "uid"},
+	}
+	legacy := []metav1.ManagedFieldsEntry{{Manager: "manager", Operation: metav1.ManagedFieldsOperationUpdate}}
+
+	tests := []struct {
+		controlled bool
+		expected   int
+		msg        string
+	}{
+		{
+			controlled: true,
+			expected:   0,
+			msg:        "object controlled by the NginxIngressController",
+		},
+		{
+			controlled: false,
+			expected:   1,
+			msg:        "object of another client",
+		},
+	}
+
+	for _, test := range tests {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default", ManagedFields: legacy}}
+		s := newCATestScheme(t)
+		if test.controlled {
+			if err := controllerutil.SetControllerReference(instance, cm, s); err != nil {
+				t.Fatalf("SetControllerReference() returned unexpected error %v", err)
+			}
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(cm).Build()
+		r := &NginxIngressControllerReconciler{Client: c, Scheme: s}
+
+		existing := &corev1.ConfigMap{}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(cm), existing); err != nil {
+			t.Fatalf("failed to get the ConfigMap: %v", err)
+		}
+		if err := r.releaseLegacyFields(context.Background(), instance, existing); err != nil {
+			t.Fatalf("releaseLegacyFields() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+
+		result := &corev1.ConfigMap{}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(cm), result); err != nil {
+			t.Fatalf("failed to get the ConfigMap: %v", err)
+		}
+		var entries int
+		for _, e := range result.ManagedFields {
+			if e.Manager != "" {
+				entries++
+			}
+		}
+		if entries != test.expected {
+			t.Errorf("releaseLegacyFields() left %v managed fields entries but expected %v for the case of %v", entries, test.expected, test.msg)
+		}
+	}
+}
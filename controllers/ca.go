@@ -0,0 +1,371 @@
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// caCertificateDuration is the validity of the certificate of the Operator CA.
+const caCertificateDuration = 10 * 365 * 24 * time.Hour
+
+// caRenewBefore is how long before the expiry the Operator CA is rotated. It is longer than the validity of the certificates
+// issued with the default duration, so they are not capped at the expiry of the CA.
+const caRenewBefore = 2 * 365 * 24 * time.Hour
+
+// previousCACertKey is the key of the certificate of the previous CA in the Secret of the CA. The previous certificate is
+// published in the CA bundle until it expires, so the clients trust the certificates it issued while they are reissued.
+const previousCACertKey = "previous-ca.crt"
+
+// caCertKey is the key of the CA certificate in the Secret of the CA, in the ConfigMap with the CA bundle and in the
+// Secrets issued by the CA.
+const caCertKey = "ca.crt"
+
+// CertificateAuthority is the certificate authority managed by the Operator. Its key and certificate are stored in a Secret
+// in the namespace of the Operator, and its certificate is published in a ConfigMap with the same name.
+type CertificateAuthority struct {
+	// Client writes the Secret and the ConfigMap of the CA.
+	Client client.Client
+	// Reader reads the Secret and the ConfigMap of the CA. The namespace of the Operator is not necessarily watched by the manager,
+	// so the reader must not be backed by the cache.
+	Reader client.Reader
+	// Namespace is the namespace of the Operator.
+	Namespace string
+	// Name is the name of the Secret and the ConfigMap of the CA.
+	Name string
+	Log  logr.Logger
+}
+
+// caKeyPair is the parsed key and certificate of the Operator CA.
+type caKeyPair struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     crypto.Signer
+	// bundlePEM is the certificate of the CA followed by the certificate of the previous CA, if it is still valid.
+	bundlePEM []byte
+}
+
+// id returns an identifier of the CA certificate, so the certificates issued by a previous CA are reissued.
+func (ca *caKeyPair) id() string {
+	sum := sha256.Sum256(ca.cert.Raw)
+	return "operator-ca:" + hex.EncodeToString(sum[:8])
+}
+
+// keyPair returns the key and certificate of the CA, creating the CA on first use and rotating it caRenewBefore its expiry, and
+// publishes the CA bundle in the ConfigMap. A Secret of the CA that cannot be parsed is not replaced, so the issued certificates
+// are not invalidated by mistake. To rotate the CA earlier, delete its Secret.
+func (c *CertificateAuthority) keyPair(ctx context.Context) (*caKeyPair, error) {
+	key := client.ObjectKey{Namespace: c.Namespace, Name: c.Name}
+
+	secret := &corev1.Secret{}
+	err := c.Reader.Get(ctx, key, secret)
+	if err != nil && errors.IsNotFound(err) {
+		secret, err = generateCASecret(c.Namespace, c.Name, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		err = c.Client.Create(ctx, secret)
+		if err != nil && errors.IsAlreadyExists(err) {
+			err = c.Reader.Get(ctx, key, secret)
+		} else if err == nil {
+			c.Log.Info("Created the certificate authority of the Operator", "Secret.Namespace", c.Namespace, "Secret.Name", c.Name)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := parseCASecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Secret %v/%v of the Operator CA: %w", c.Namespace, c.Name, err)
+	}
+
+	if defaultCertificateRenewal(ca.cert.NotAfter, caRenewBefore, time.Now()) == 0 {
+		ca, err = c.rotate(ctx, secret, ca)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ca, c.publish(ctx, ca)
+}
+
+// rotate replaces the key and certificate of the CA in its Secret, keeping the certificate of the current CA as the previous
+// certificate. The certificates issued by the current CA are reissued, as the CA is part of their parameters.
+func (c *CertificateAuthority) rotate(ctx context.Context, secret *corev1.Secret, current *caKeyPair) (*caKeyPair, error) {
+	rotated, err := generateCASecret(c.Namespace, c.Name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	secret.Data = rotated.Data
+	secret.Data[previousCACertKey] = current.certPEM
+
+	// The update fails with a conflict when the CA was rotated concurrently, and the rotated CA is read again.
+	if err := c.Client.Update(ctx, secret); err != nil {
+		if !errors.IsConflict(err) {
+			return nil, err
+		}
+		if err := c.Reader.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+			return nil, err
+		}
+	} else {
+		c.Log.Info("Rotated the certificate authority of the Operator", "Secret.Namespace", c.Namespace, "Secret.Name", c.Name,
+			"NotAfter", current.cert.NotAfter)
+	}
+
+	ca, err := parseCASecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Secret %v/%v of the Operator CA: %w", c.Namespace, c.Name, err)
+	}
+	return ca, nil
+}
+
+// publish creates or updates the ConfigMap with the CA bundle.
+func (c *CertificateAuthority) publish(ctx context.Context, ca *caKeyPair) error {
+	cm := &corev1.ConfigMap{}
+	err := c.Reader.Get(ctx, client.ObjectKey{Namespace: c.Namespace, Name: c.Name}, cm)
+	if err != nil && errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: c.Name, Namespace: c.Namespace},
+			Data:       map[string]string{caCertKey: string(ca.bundlePEM)},
+		}
+		return c.Client.Create(ctx, cm)
+	} else if err != nil {
+		return err
+	}
+
+	if cm.Data[caCertKey] == string(ca.bundlePEM) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[caCertKey] = string(ca.bundlePEM)
+	return c.Client.Update(ctx, cm)
+}
+
+// generateCASecret returns a Secret with a new key and self-signed certificate of the CA, valid from notBefore.
+func generateCASecret(namespace string, name string, notBefore time.Time) (*corev1.Secret, error) {
+	priv, key, err := generatePrivateKey(k8sv1beta1.KeyAlgorithmRSA2048)
+	if err != nil {
+		return nil, err
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"NGINX Inc"},
+			CommonName:   "NGINX Ingress Operator CA",
+		},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(caCertificateDuration),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, priv.Public(), priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes}),
+			corev1.TLSPrivateKeyKey: key,
+		},
+		Type: corev1.SecretTypeTLS,
+	}, nil
+}
+
+// parseCASecret parses the key and certificate of the CA, and the certificate of the previous CA.
+func parseCASecret(secret *corev1.Secret) (*caKeyPair, error) {
+	certPEM := secret.Data[corev1.TLSCertKey]
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("the certificate is not a CA certificate")
+	}
+
+	key, err := parsePrivateKey(secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+
+	bundlePEM := certPEM
+	if previous, err := parseCertificate(secret.Data[previousCACertKey]); err == nil && time.Now().Before(previous.NotAfter) {
+		bundlePEM = append(append([]byte{}, certPEM...), secret.Data[previousCACertKey]...)
+	}
+
+	return &caKeyPair{cert: cert, certPEM: certPEM, key: key, bundlePEM: bundlePEM}, nil
+}
+
+// parsePrivateKey parses a PEM encoded private key in the formats written by generatePrivateKey.
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded private key found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+}
+
+// wildcardSecretName returns the name of the Secret with the wildcard certificate issued by the Operator CA.
+func wildcardSecretName(instance *k8sv1beta1.NginxIngressController) string {
+	return fmt.Sprintf("%v-wildcard-tls", instance.Name)
+}
+
+// prometheusSecretName returns the name of the Secret with the certificate of the Prometheus endpoint issued by the Operator CA.
+func prometheusSecretName(instance *k8sv1beta1.NginxIngressController) string {
+	return fmt.Sprintf("%v-prometheus-tls", instance.Name)
+}
+
+// issuesWildcardCertificate returns whether the wildcard TLS Secret is issued by the Operator CA.
+func issuesWildcardCertificate(instance *k8sv1beta1.NginxIngressController) bool {
+	return instance.Spec.WildcardTLS == nil && instance.Spec.OperatorCA != nil && instance.Spec.OperatorCA.WildcardDomain != ""
+}
+
+// issuesPrometheusCertificate returns whether the TLS Secret of the Prometheus endpoint is issued by the Operator CA.
+func issuesPrometheusCertificate(instance *k8sv1beta1.NginxIngressController) bool {
+	return instance.Spec.Prometheus != nil && instance.Spec.Prometheus.Enable && instance.Spec.Prometheus.Secret == nil &&
+		instance.Spec.OperatorCA != nil && instance.Spec.OperatorCA.Prometheus
+}
+
+// issuedCertificateParams returns the parameters of a certificate issued by the Operator CA for the DNS names.
+func issuedCertificateParams(dnsNames ...string) certificateParams {
+	return certificateParams{
+		CommonName:   dnsNames[0],
+		DNSNames:     dnsNames,
+		KeyAlgorithm: k8sv1beta1.KeyAlgorithmRSA2048,
+		Duration:     k8sv1beta1.DefaultCertificateDuration,
+	}
+}
+
+// operatorCAKeyPair returns the key and certificate of the Operator CA.
+func (r *NginxIngressControllerReconciler) operatorCAKeyPair(ctx context.Context) (*caKeyPair, error) {
+	if r.CA == nil {
+		return nil, fmt.Errorf("the Operator CA is not available because the namespace of the Operator is unknown, set the OPERATOR_NAMESPACE environment variable")
+	}
+	return r.CA.keyPair(ctx)
+}
+
+// reconcileIssuedSecrets creates the Secrets with the wildcard certificate and the certificate of the Prometheus endpoint issued
+// by the Operator CA, regenerates the certificates when they are about to expire, and removes the Secrets that are no longer used,
+// unless cert-manager issues them.
+// It returns the time to wait before a certificate must be regenerated.
+func (r *NginxIngressControllerReconciler) reconcileIssuedSecrets(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController) (time.Duration, error) {
+	wildcard := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: wildcardSecretName(instance), Namespace: instance.Namespace}}
+	prometheus := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: prometheusSecretName(instance), Namespace: instance.Namespace}}
+
+	var issued []certificateSecret
+	if issuesWildcardCertificate(instance) {
+		domain := instance.Spec.OperatorCA.WildcardDomain
+		issued = append(issued, certificateSecret{
+			name:          wildcard.Name,
+			params:        issuedCertificateParams("*."+domain, domain),
+			description:   "wildcard certificate",
+			renewedReason: eventReasonCertificateRenewed,
+		})
+	} else if !certManagerIssuesWildcard(instance) {
+		if err := r.deleteIfControlled(ctx, instance, wildcard); err != nil {
+			return 0, err
+		}
+	}
+	if issuesPrometheusCertificate(instance) {
+		service := fmt.Sprintf("%v.%v.svc", instance.Name, instance.Namespace)
+		issued = append(issued, certificateSecret{
+			name:          prometheus.Name,
+			params:        issuedCertificateParams(service, service+".cluster.local"),
+			description:   "certificate of the Prometheus endpoint",
+			renewedReason: eventReasonCertificateRenewed,
+		})
+	} else if !certManagerIssuesPrometheus(instance) {
+		if err := r.deleteIfControlled(ctx, instance, prometheus); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(issued) == 0 {
+		return 0, nil
+	}
+
+	ca, err := r.operatorCAKeyPair(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var requeueAfter []time.Duration
+	for _, cs := range issued {
+		cs.ca = ca
+		cs.renewBefore = k8sv1beta1.DefaultCertificateRenewBefore
+		notAfter, created, err := r.reconcileCertificateSecret(ctx, log, instance, cs)
+		if err != nil {
+			return 0, err
+		}
+		if created {
+			r.recordCreated(instance, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: cs.name}})
+		}
+		if !notAfter.IsZero() {
+			requeueAfter = append(requeueAfter, cs.renewal(notAfter, time.Now()))
+		}
+	}
+
+	return earliestRequeue(requeueAfter...), nil
+}
+
+// deleteIfControlled deletes the Secret if it exists and is controlled by the NginxIngressController, so a Secret
+// with the same name created by the user is kept.
+func (r *NginxIngressControllerReconciler) deleteIfControlled(ctx context.Context, instance *k8sv1beta1.NginxIngressController, secret *corev1.Secret) error {
+	err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(secret, instance) {
+		return nil
+	}
+	return r.deleteIfExists(ctx, instance, secret)
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCATestScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add client-go scheme: (%v)", err)
+	}
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
+	}
+	return s
+}
+
+func TestCertificateAuthorityKeyPair(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(newCATestScheme(t)).Build()
+	ca := &CertificateAuthority{Client: c, Reader: c, Namespace: "nginx-ingress-operator", Name: "nginx-ingress-operator-ca", Log: logr.Discard()}
+
+	first, err := ca.keyPair(context.Background())
+	if err != nil {
+		t.Fatalf("keyPair() returned unexpected error %v", err)
+	}
+	if !first.cert.IsCA {
+		t.Errorf("keyPair() returned a certificate that is not a CA certificate")
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: ca.Namespace, Name: ca.Name}, cm); err != nil {
+		t.Fatalf("keyPair() did not publish the CA bundle: %v", err)
+	}
+	if cm.Data[caCertKey] != string(first.certPEM) {
+		t.Errorf("keyPair() published the CA bundle %q but expected %q", cm.Data[caCertKey], first.certPEM)
+	}
+
+	cm.Data[caCertKey] = "stale"
+	if err := c.Update(context.Background(), cm); err != nil {
+		t.Fatalf("failed to update the ConfigMap: %v", err)
+	}
+
+	second, err := ca.keyPair(context.Background())
+	if err != nil {
+		t.Fatalf("keyPair() returned unexpected error %v", err)
+	}
+	if second.id() != first.id() {
+		t.Errorf("keyPair() returned a new CA %v but expected the existing CA %v", second.id(), first.id())
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(cm), cm); err != nil {
+		t.Fatalf("failed to get the ConfigMap: %v", err)
+	}
+	if cm.Data[caCertKey] != string(first.certPEM) {
+		t.Errorf("keyPair() did not update the stale CA bundle")
+	}
+}
+
+func TestGenerateCertificateSignedByCA(t *testing.T) {
+	secret, err := generateCASecret("nginx-ingress-operator", "nginx-ingress-operator-ca", time.Now())
+	if err != nil {
+		t.Fatalf("generateCASecret() returned unexpected error %v", err)
+	}
+	ca, err := parseCASecret(secret)
+	if err != nil {
+		t.Fatalf("parseCASecret() returned unexpected error %v", err)
+	}
+
+	params := issuedCertificateParams("*.example.com", "example.com")
+	params.Duration = 2 * caCertificateDuration
+	crt, _, err := generateCertificate(params, ca)
+	if err != nil {
+		t.Fatalf("generateCertificate() returned unexpected error %v", err)
+	}
+
+	block, _ := pem.Decode(crt)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse the certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "www.example.com", Roots: roots}); err != nil {
+		t.Errorf("generateCertificate() returned a certificate that is not trusted by the CA: %v", err)
+	}
+	if cert.NotAfter.After(ca.cert.NotAfter) {
+		t.Errorf("generateCertificate() returned a certificate valid until %v after the CA expires at %v", cert.NotAfter, ca.cert.NotAfter)
+	}
+}
+
+func TestReconcileIssuedSecrets(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			OperatorCA: &k8sv1beta1.OperatorCA{WildcardDomain: "example.com"},
+		},
+	}
+	userSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: prometheusSecretName(instance), Namespace: instance.Namespace}}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(userSecret).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NginxIngressControllerReconciler{
+		Client:   c,
+		Scheme:   s,
+		Recorder: recorder,
+		CA:       &CertificateAuthority{Client: c, Reader: c, Namespace: "nginx-ingress-operator", Name: "nginx-ingress-operator-ca", Log: logr.Discard()},
+	}
+
+	requeueAfter, err := r.reconcileIssuedSecrets(context.Background(), logr.Discard(), instance)
+	if err != nil {
+		t.Fatalf("reconcileIssuedSecrets() returned unexpected error %v", err)
+	}
+	if requeueAfter <= 0 {
+		t.Errorf("reconcileIssuedSecrets() returned requeueAfter %v but expected the time until the renewal", requeueAfter)
+	}
+
+	wildcard := &corev1.Secret{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: instance.Namespace, Name: wildcardSecretName(instance)}, wildcard); err != nil {
+		t.Fatalf("reconcileIssuedSecrets() did not create the wildcard Secret: %v", err)
+	}
+	if len(wildcard.Data[caCertKey]) == 0 {
+		t.Errorf("reconcileIssuedSecrets() created the wildcard Secret without the CA certificate")
+	}
+	if !metav1.IsControlledBy(wildcard, instance) {
+		t.Errorf("reconcileIssuedSecrets() created the wildcard Secret without the controller reference")
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(userSecret), &corev1.Secret{}); err != nil {
+		t.Errorf("reconcileIssuedSecrets() removed the Secret not managed by the Operator: %v", err)
+	}
+
+	instance.Spec.OperatorCA = nil
+	if _, err := r.reconcileIssuedSecrets(context.Background(), logr.Discard(), instance); err != nil {
+		t.Fatalf("reconcileIssuedSecrets() returned unexpected error %v", err)
+	}
+	err = c.Get(context.Background(), client.ObjectKeyFromObject(wildcard), &corev1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("reconcileIssuedSecrets() did not remove the wildcard Secret that is no longer used: %v", err)
+	}
+
+	expectedEvents := []string{"Normal Created Created Secret my-nginx-ingress-wildcard-tls", "Normal Deleted Deleted Secret my-nginx-ingress-wildcard-tls"}
+	if diff := cmp.Diff(expectedEvents, recordedEvents(recorder)); diff != "" {
+		t.Errorf("reconcileIssuedSecrets() recorded events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReconcileIssuedSecretsWithoutCA(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			OperatorCA: &k8sv1beta1.OperatorCA{WildcardDomain: "example.com"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	if _, err := r.reconcileIssuedSecrets(context.Background(), logr.Discard(), instance); err == nil {
+		t.Errorf("reconcileIssuedSecrets() returned no error when the Operator CA is not available")
+	}
+}
+
+func TestCertificateAuthorityRotation(t *testing.T) {
+	// The CA expires within caRenewBefore.
+	expiring, err := generateCASecret("nginx-ingress-operator", "nginx-ingress-operator-ca", time.Now().Add(-caCertificateDuration+caRenewBefore/2))
+	if err != nil {
+		t.Fatalf("generateCASecret() returned unexpected error %v", err)
+	}
+	previous, err := parseCASecret(expiring)
+	if err != nil {
+		t.Fatalf("parseCASecret() returned unexpected error %v", err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(newCATestScheme(t)).WithObjects(expiring).Build()
+	ca := &CertificateAuthority{Client: c, Reader: c, Namespace: "nginx-ingress-operator", Name: "nginx-ingress-operator-ca", Log: logr.Discard()}
+
+	rotated, err := ca.keyPair(context.Background())
+	if err != nil {
+		t.Fatalf("keyPair() returned unexpected error %v", err)
+	}
+	if rotated.id() == previous.id() {
+		t.Fatalf("keyPair() did not rotate the CA that expires at %v", previous.cert.NotAfter)
+	}
+	if defaultCertificateRenewal(rotated.cert.NotAfter, caRenewBefore, time.Now()) == 0 {
+		t.Errorf("keyPair() rotated the CA to a CA that expires at %v", rotated.cert.NotAfter)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(expiring), secret); err != nil {
+		t.Fatalf("failed to get the Secret of the CA: %v", err)
+	}
+	if string(secret.Data[previousCACertKey]) != string(previous.certPEM) {
+		t.Errorf("keyPair() did not keep the certificate of the previous CA in the Secret")
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: ca.Namespace, Name: ca.Name}, cm); err != nil {
+		t.Fatalf("keyPair() did not publish the CA bundle: %v", err)
+	}
+	expectedBundle := string(rotated.certPEM) + string(previous.certPEM)
+	if cm.Data[caCertKey] != expectedBundle {
+		t.Errorf("keyPair() published the CA bundle %q but expected the certificates of the CA and the previous CA %q", cm.Data[caCertKey], expectedBundle)
+	}
+}
+
+func TestCertificateSecretRenewal(t *testing.T) {
+	now := time.Now()
+	secret, err := generateCASecret("nginx-ingress-operator", "nginx-ingress-operator-ca", now.Add(-caCertificateDuration+3*365*24*time.Hour))
+	if err != nil {
+		t.Fatalf("generateCASecret() returned unexpected error %v", err)
+	}
+	ca, err := parseCASecret(secret)
+	if err != nil {
+		t.Fatalf("parseCASecret() returned unexpected error %v", err)
+	}
+
+	tests := []struct {
+		ca       *caKeyPair
+		notAfter time.Time
+		expected time.Duration
+		msg      string
+	}{
+		{
+			notAfter: now.Add(10 * 24 * time.Hour),
+			expected: 0,
+			msg:      "self-signed certificate within renewBefore",
+		},
+		{
+			ca:       ca,
+			notAfter: now.Add(40 * 24 * time.Hour),
+			expected: 10 * 24 * time.Hour,
+			msg:      "certificate issued by the CA",
+		},
+		{
+			ca:       ca,
+			notAfter: ca.cert.NotAfter,
+			expected: ca.cert.NotAfter.Add(-caRenewBefore).Sub(now),
+			msg:      "certificate capped at the expiry of the CA, regenerated when the CA is rotated",
+		},
+	}
+
+	for _, test := range tests {
+		cs := certificateSecret{ca: test.ca, renewBefore: k8sv1beta1.DefaultCertificateRenewBefore}
+		if result := cs.renewal(test.notAfter, now); result != test.expected {
+			t.Errorf("renewal() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
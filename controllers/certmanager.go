@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certManagerGroup is the API group of cert-manager and the default group of the issuers.
+const certManagerGroup = "cert-manager.io"
+
+// certManagerPollInterval is the time to wait before checking again the cert-manager Certificates that are not ready.
+// The Operator doesn't watch the Certificates, so it doesn't depend on cert-manager being installed.
+const certManagerPollInterval = 10 * time.Second
+
+// certManagerCertificateNameAnnotation is set by cert-manager on the Secrets it issues, with the name of their Certificate.
+const certManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
+
+// certificateGVK is the kind of the cert-manager Certificates. They are handled as unstructured objects,
+// so the Operator doesn't depend on the cert-manager API.
+var certificateGVK = schema.GroupVersionKind{Group: certManagerGroup, Version: "v1", Kind: "Certificate"}
+
+// Reasons of the CertificatesReady condition.
+const (
+	reasonCertificatesIssued  = "CertificatesIssued"
+	reasonCertificatesPending = "CertificatesPending"
+	reasonCertificatesFailed  = "CertificatesFailed"
+)
+
+func certManagerIssuesDefaultServer(instance *k8sv1beta1.NginxIngressController) bool {
+	return instance.Spec.DefaultSecret == nil && instance.Spec.CertManager != nil && instance.Spec.CertManager.DefaultServer
+}
+
+func certManagerIssuesWildcard(instance *k8sv1beta1.NginxIngressController) bool {
+	return instance.Spec.WildcardTLS == nil && instance.Spec.CertManager != nil && instance.Spec.CertManager.WildcardDomain != ""
+}
+
+func certManagerIssuesPrometheus(instance *k8sv1beta1.NginxIngressController) bool {
+	return instance.Spec.Prometheus != nil && instance.Spec.Prometheus.Enable && instance.Spec.Prometheus.Secret == nil &&
+		instance.Spec.CertManager != nil && instance.Spec.CertManager.Prometheus
+}
+
+// newCertificate returns an empty cert-manager Certificate with the name and the namespace of the NginxIngressController.
+func newCertificate(instance *k8sv1beta1.NginxIngressController, name string) *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	cert.SetName(name)
+	cert.SetNamespace(instance.Namespace)
+	return cert
+}
+
+// certificatePrivateKey returns the privateKey field of a cert-manager Certificate for the key algorithm.
+func certificatePrivateKey(algorithm k8sv1beta1.KeyAlgorithm) map[string]interface{} {
+	switch algorithm {
+	case k8sv1beta1.KeyAlgorithmRSA4096:
+		return map[string]interface{}{"algorithm": "RSA", "size": int64(4096)}
+	case k8sv1beta1.KeyAlgorithmECDSAP256:
+		return map[string]interface{}{"algorithm": "ECDSA", "size": int64(256)}
+	case k8sv1beta1.KeyAlgorithmECDSAP384:
+		return map[string]interface{}{"algorithm": "ECDSA", "size": int64(384)}
+	case k8sv1beta1.KeyAlgorithmEd25519:
+		return map[string]interface{}{"algorithm": "Ed25519"}
+	}
+	return map[string]interface{}{"algorithm": "RSA", "size": int64(2048)}
+}
+
+// certificateForNginxIngressController returns a cert-manager Certificate that stores the certificate for the parameters in the
+// Secret with the same name.
+func certificateForNginxIngressController(instance *k8sv1beta1.NginxIngressController, name string, params certificateParams, renewBefore time.Duration) *unstructured.Unstructured {
+	issuerRef := instance.Spec.CertManager.IssuerRef
+	issuer := map[string]interface{}{"name": issuerRef.Name, "kind": "Issuer", "group": certManagerGroup}
+	if issuerRef.Kind != "" {
+		issuer["kind"] = issuerRef.Kind
+	}
+	if issuerRef.Group != "" {
+		issuer["group"] = issuerRef.Group
+	}
+
+	privateKey := certificatePrivateKey(params.KeyAlgorithm)
+	// Key encipherment only applies to RSA keys.
+	usages := []interface{}{"server auth", "digital signature"}
+	if privateKey["algorithm"] == "RSA" {
+		usages = append(usages, "key encipherment")
+	}
+
+	spec := map[string]interface{}{
+		"secretName":  name,
+		"commonName":  params.CommonName,
+		"dnsNames":    stringsToInterfaces(params.DNSNames),
+		"duration":    params.Duration.String(),
+		"renewBefore": renewBefore.String(),
+		"privateKey":  privateKey,
+		"usages":      usages,
+		"issuerRef":   issuer,
+	}
+	if len(params.IPAddresses) > 0 {
+		spec["ipAddresses"] = stringsToInterfaces(params.IPAddresses)
+	}
+
+	cert := newCertificate(instance, name)
+	cert.Object["spec"] = spec
+	return cert
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+	}
+	return result
+}
+
+// certificateReady returns whether the Ready condition of the cert-manager Certificate is true, and the message of the condition.
+func certificateReady(cert *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		message, _ := condition["message"].(string)
+		return condition["status"] == "True", message
+	}
+	return false, "waiting for cert-manager to process the Certificate"
+}
+
+// certificateNotAfterFromStatus returns the expiry time reported in the status of the cert-manager Certificate, if any.
+func certificateNotAfterFromStatus(cert *unstructured.Unstructured) *metav1.Time {
+	notAfter, found, _ := unstructured.NestedString(cert.Object, "status", "notAfter")
+	if !found {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, notAfter)
+	if err != nil {
+		return nil
+	}
+	result := metav1.NewTime(t)
+	return &result
+}
+
+// reconcileCertManagerCertificates applies the cert-manager Certificates of the TLS Secrets issued by cert-manager, removes the
+// Certificates that are no longer used and reports whether all the Certificates are ready in the CertificatesReady condition.
+// It returns whether the Certificates are ready, so the Ingress Controller is not deployed before its TLS Secrets exist.
+func (r *NginxIngressControllerReconciler) reconcileCertManagerCertificates(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController) (bool, error) {
+	if instance.Spec.CertManager == nil {
+		// The condition is only set when certManager was used, so the Certificates are only looked up after certManager is removed,
+		// and the Operator works without cert-manager installed.
+		if meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionCertificatesReady) == nil {
+			return true, nil
+		}
+		for _, name := range []string{instance.Name, wildcardSecretName(instance), prometheusSecretName(instance)} {
+			if err := r.deleteCertificate(ctx, instance, name); err != nil {
+				return false, err
+			}
+		}
+		meta.RemoveStatusCondition(&instance.Status.Conditions, k8sv1beta1.ConditionCertificatesReady)
+		return true, nil
+	}
+
+	desired := map[string]*unstructured.Unstructured{}
+	if certManagerIssuesDefaultServer(instance) {
+		desired[instance.Name] = certificateForNginxIngressController(instance, instance.Name, defaultCertificateParams(instance), defaultCertificateRenewBeforeFor(instance))
+	}
+	if certManagerIssuesWildcard(instance) {
+		domain := instance.Spec.CertManager.WildcardDomain
+		desired[wildcardSecretName(instance)] = certificateForNginxIngressController(instance, wildcardSecretName(instance),
+			issuedCertificateParams("*."+domain, domain), k8sv1beta1.DefaultCertificateRenewBefore)
+	}
+	if certManagerIssuesPrometheus(instance) {
+		service := fmt.Sprintf("%v.%v.svc", instance.Name, instance.Namespace)
+		desired[prometheusSecretName(instance)] = certificateForNginxIngressController(instance, prometheusSecretName(instance),
+			issuedCertificateParams(service, service+".cluster.local"), k8sv1beta1.DefaultCertificateRenewBefore)
+	}
+
+	var pending []string
+	for _, name := range []string{instance.Name, wildcardSecretName(instance), prometheusSecretName(instance)} {
+		cert, ok := desired[name]
+		if !ok {
+			if err := r.deleteCertificate(ctx, instance, name); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if err := ctrl.SetControllerReference(instance, cert, r.Scheme); err != nil {
+			return false, err
+		}
+		if err := r.apply(ctx, instance, cert); err != nil {
+			if meta.IsNoMatchError(err) {
+				err = fmt.Errorf("the cert-manager Certificate API is not available, install cert-manager: %w", err)
+			}
+			setCondition(instance, k8sv1beta1.ConditionCertificatesReady, metav1.ConditionFalse, reasonCertificatesFailed, err.Error())
+			return false, err
+		}
+
+		ready, message := certificateReady(cert)
+		if !ready {
+			pending = append(pending, fmt.Sprintf("%v: %v", name, message))
+		}
+		if name == instance.Name {
+			instance.Status.DefaultCertificateExpiry = certificateNotAfterFromStatus(cert)
+		}
+	}
+
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		msg := fmt.Sprintf("Waiting for the cert-manager Certificates to be ready: %v", strings.Join(pending, "; "))
+		log.Info(msg)
+		setCondition(instance, k8sv1beta1.ConditionCertificatesReady, metav1.ConditionFalse, reasonCertificatesPending, msg)
+		return false, nil
+	}
+
+	setCondition(instance, k8sv1beta1.ConditionCertificatesReady, metav1.ConditionTrue, reasonCertificatesIssued, "")
+	return true, nil
+}
+
+// deleteCertificate deletes the cert-manager Certificate if it exists and is controlled by the NginxIngressController, and the
+// Secret cert-manager issued for it. cert-manager doesn't delete the Secret with the Certificate, and the Secret is not
+// controlled by the NginxIngressController, so it would otherwise be used as is, and never renewed, by the Operator.
+func (r *NginxIngressControllerReconciler) deleteCertificate(ctx context.Context, instance *k8sv1beta1.NginxIngressController, name string) error {
+	cert := newCertificate(instance, name)
+	err := r.Get(ctx, client.ObjectKeyFromObject(cert), cert)
+	if err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(cert, instance) {
+		return nil
+	}
+	if err := r.deleteIfExists(ctx, instance, cert); err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Get(ctx, client.ObjectKey{Name: name, Namespace: instance.Namespace}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if secret.Annotations[certManagerCertificateNameAnnotation] != name || metav1.IsControlledBy(secret, instance) {
+		return nil
+	}
+	return r.deleteIfExists(ctx, instance, secret)
+}
@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCertificateForNginxIngressController(t *testing.T) {
+	params := certificateParams{
+		CommonName:   "ingress.example.com",
+		DNSNames:     []string{"ingress.example.com"},
+		IPAddresses:  []string{"10.0.0.1"},
+		KeyAlgorithm: k8sv1beta1.KeyAlgorithmECDSAP256,
+		Duration:     90 * 24 * time.Hour,
+	}
+
+	tests := []struct {
+		issuerRef k8sv1beta1.CertManagerIssuerReference
+		params    certificateParams
+		expected  map[string]interface{}
+		msg       string
+	}{
+		{
+			issuerRef: k8sv1beta1.CertManagerIssuerReference{Name: "my-issuer"},
+			params:    issuedCertificateParams("*.example.com", "example.com"),
+			expected: map[string]interface{}{
+				"secretName":  "my-nginx-ingress",
+				"commonName":  "*.example.com",
+				"dnsNames":    []interface{}{"*.example.com", "example.com"},
+				"duration":    "8760h0m0s",
+				"renewBefore": "720h0m0s",
+				"privateKey":  map[string]interface{}{"algorithm": "RSA", "size": int64(2048)},
+				"usages":      []interface{}{"server auth", "digital signature", "key encipherment"},
+				"issuerRef":   map[string]interface{}{"name": "my-issuer", "kind": "Issuer", "group": "cert-manager.io"},
+			},
+			msg: "issuer with defaults",
+		},
+		{
+			issuerRef: k8sv1beta1.CertManagerIssuerReference{Name: "my-issuer", Kind: "ClusterIssuer", Group: "example.com"},
+			params:    params,
+			expected: map[string]interface{}{
+				"secretName":  "my-nginx-ingress",
+				"commonName":  "ingress.example.com",
+				"dnsNames":    []interface{}{"ingress.example.com"},
+				"ipAddresses": []interface{}{"10.0.0.1"},
+				"duration":    "2160h0m0s",
+				"renewBefore": "720h0m0s",
+				"privateKey":  map[string]interface{}{"algorithm": "ECDSA", "size": int64(256)},
+				"usages":      []interface{}{"server auth", "digital signature"},
+				"issuerRef":   map[string]interface{}{"name": "my-issuer", "kind": "ClusterIssuer", "group": "example.com"},
+			},
+			msg: "external cluster issuer with ECDSA key",
+		},
+	}
+
+	for _, test := range tests {
+		instance := &k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+			Spec: k8sv1beta1.NginxIngressControllerSpec{
+				CertManager: &k8sv1beta1.CertManager{IssuerRef: test.issuerRef},
+			},
+		}
+
+		cert := certificateForNginxIngressController(instance, instance.Name, test.params, k8sv1beta1.DefaultCertificateRenewBefore)
+		if cert.GroupVersionKind() != certificateGVK || cert.GetNamespace() != instance.Namespace || cert.GetName() != instance.Name {
+			t.Errorf("certificateForNginxIngressController() returned %v %v/%v for the case of %v", cert.GroupVersionKind(), cert.GetNamespace(), cert.GetName(), test.msg)
+		}
+		if diff := cmp.Diff(test.expected, cert.Object["spec"]); diff != "" {
+			t.Errorf("certificateForNginxIngressController() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestCertificateReady(t *testing.T) {
+	tests := []struct {
+		status          map[string]interface{}
+		expected        bool
+		expectedMessage string
+		msg             string
+	}{
+		{
+			status:          nil,
+			expected:        false,
+			expectedMessage: "waiting for cert-manager to process the Certificate",
+			msg:             "no status",
+		},
+		{
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Issuing", "status": "True"},
+					map[string]interface{}{"type": "Ready", "status": "False", "message": "Issuing certificate as Secret does not exist"},
+				},
+			},
+			expected:        false,
+			expectedMessage: "Issuing certificate as Secret does not exist",
+			msg:             "not ready",
+		},
+		{
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True", "message": "Certificate is up to date and has not expired"},
+				},
+			},
+			expected:        true,
+			expectedMessage: "Certificate is up to date and has not expired",
+			msg:             "ready",
+		},
+	}
+
+	for _, test := range tests {
+		cert := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if test.status != nil {
+			cert.Object["status"] = test.status
+		}
+		ready, message := certificateReady(cert)
+		if ready != test.expected || message != test.expectedMessage {
+			t.Errorf("certificateReady() returned %v, %q but expected %v, %q for the case of %v", ready, message, test.expected, test.expectedMessage, test.msg)
+		}
+	}
+}
+
+func TestCertificateNotAfterFromStatus(t *testing.T) {
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"notAfter": "2023-01-01T00:00:00Z"},
+	}}
+	result := certificateNotAfterFromStatus(cert)
+	if result == nil || !result.Time.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("certificateNotAfterFromStatus() returned %v but expected 2023-01-01T00:00:00Z", result)
+	}
+
+	if result := certificateNotAfterFromStatus(&unstructured.Unstructured{Object: map[string]interface{}{}}); result != nil {
+		t.Errorf("certificateNotAfterFromStatus() returned %v but expected nil for a Certificate without status", result)
+	}
+}
+
+func TestReconcileCertManagerCertificatesRemoved(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+	}
+	setCondition(instance, k8sv1beta1.ConditionCertificatesReady, metav1.ConditionTrue, reasonCertificatesIssued, "")
+
+	owned := newCertificate(instance, wildcardSecretName(instance))
+	if err := ctrl.SetControllerReference(instance, owned, s); err != nil {
+		t.Fatalf("SetControllerReference() returned unexpected error %v", err)
+	}
+	unowned := newCertificate(instance, prometheusSecretName(instance))
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(owned, unowned).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+	ready, err := r.reconcileCertManagerCertificates(context.Background(), logr.Discard(), instance)
+	if err != nil {
+		t.Fatalf("reconcileCertManagerCertificates() returned unexpected error %v", err)
+	}
+	if !ready {
+		t.Errorf("reconcileCertManagerCertificates() returned not ready when certManager is not set")
+	}
+
+	err = c.Get(context.Background(), client.ObjectKeyFromObject(owned), newCertificate(instance, owned.GetName()))
+	if !errors.IsNotFound(err) {
+		t.Errorf("reconcileCertManagerCertificates() did not remove the Certificate of the NginxIngressController: %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(unowned), newCertificate(instance, unowned.GetName())); err != nil {
+		t.Errorf("reconcileCertManagerCertificates() removed the Certificate not managed by the Operator: %v", err)
+	}
+	if meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionCertificatesReady) != nil {
+		t.Errorf("reconcileCertManagerCertificates() did not remove the %v condition", k8sv1beta1.ConditionCertificatesReady)
+	}
+
+	if diff := cmp.Diff([]string{"Normal Deleted Deleted Certificate my-nginx-ingress-wildcard-tls"}, recordedEvents(recorder)); diff != "" {
+		t.Errorf("reconcileCertManagerCertificates() recorded events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReconcileCertManagerDefaultServerDisabled(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			CertManager: &k8sv1beta1.CertManager{IssuerRef: k8sv1beta1.CertManagerIssuerReference{Name: "my-issuer"}},
+		},
+	}
+
+	// The Certificate and the Secret issued by cert-manager while certManager.defaultServer was set.
+	cert := newCertificate(instance, instance.Name)
+	if err := ctrl.SetControllerReference(instance, cert, s); err != nil {
+		t.Fatalf("SetControllerReference() returned unexpected error %v", err)
+	}
+	issued := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        instance.Name,
+			Namespace:   instance.Namespace,
+			Annotations: map[string]string{"cert-manager.io/certificate-name": instance.Name},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("issued"), corev1.TLSPrivateKeyKey: []byte("issued")},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cert, issued).Build()
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	if _, err := r.reconcileCertManagerCertificates(context.Background(), logr.Discard(), instance); err != nil {
+		t.Fatalf("reconcileCertManagerCertificates() returned unexpected error %v", err)
+	}
+	if _, err := r.reconcileDefaultSecret(context.Background(), logr.Discard(), instance); err != nil {
+		t.Fatalf("reconcileDefaultSecret() returned unexpected error %v", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(issued), secret); err != nil {
+		t.Fatalf("failed to get the Secret of the default server: %v", err)
+	}
+	if !metav1.IsControlledBy(secret, instance) || string(secret.Data[corev1.TLSCertKey]) == "issued" {
+		t.Errorf("the Secret issued by cert-manager was not replaced by the self-signed certificate after certManager.defaultServer was disabled")
+	}
+}
+
+func TestDeleteCertificateKeepsUserSecret(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+	}
+	cert := newCertificate(instance, instance.Name)
+	if err := ctrl.SetControllerReference(instance, cert, s); err != nil {
+		t.Fatalf("SetControllerReference() returned unexpected error %v", err)
+	}
+	user := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}}
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(cert, user).Build()
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	if err := r.deleteCertificate(context.Background(), instance, instance.Name); err != nil {
+		t.Fatalf("deleteCertificate() returned unexpected error %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(user), &corev1.Secret{}); err != nil {
+		t.Errorf("deleteCertificate() removed the Secret not issued by cert-manager: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"github.com/nginxinc/nginx-ingress-operator/controllers/scc"
+	networking "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Reasons of the CRDsInstalled and ClusterResourcesReady conditions in namespaced mode.
+const (
+	reasonCRDsMissing             = "CRDsMissing"
+	reasonClusterResourcesFound   = "ClusterResourcesFound"
+	reasonClusterResourcesMissing = "ClusterResourcesMissing"
+)
+
+// reconcileClusterResources makes sure the cluster-scoped resources required by the NginxIngressController exist. They are
+// installed by the Operator, except in namespaced mode, where they are installed by the cluster administrator and only
+// verified. It returns false when the NginxIngressController must wait for the resources.
+func (r *NginxIngressControllerReconciler) reconcileClusterResources(ctx context.Context, instance *k8sv1beta1.NginxIngressController) (bool, error) {
+	if !r.Namespaced {
+		return r.reconcileSharedResources(ctx, instance)
+	}
+
+	crdsReady, err := r.verifyCRDs(ctx, instance)
+	if err != nil {
+		return false, err
+	}
+	resourcesReady, err := r.verifyClusterResources(ctx, instance)
+	if err != nil {
+		return false, err
+	}
+	return crdsReady && resourcesReady, nil
+}
+
+// verifyCRDs checks that the KIC CRDs required by the NginxIngressController are installed and reports the outcome in the
+// CRDsInstalled condition.
+func (r *NginxIngressControllerReconciler) verifyCRDs(ctx context.Context, instance *k8sv1beta1.NginxIngressController) (bool, error) {
+	var missing []string
+	for _, name := range crdNamesForNginxIngressController(instance) {
+		if containsString(missing, name) {
+			continue
+		}
+		err := r.Reader.Get(ctx, types.NamespacedName{Name: name}, &apixv1.CustomResourceDefinition{})
+		if err != nil && errors.IsNotFound(err) {
+			missing = append(missing, name)
+		} else if err != nil {
+			return false, fmt.Errorf("error getting CRD %v: %w", name, err)
+		}
+	}
+	// The Operator doesn't update the CRDs in namespaced mode.
+	meta.RemoveStatusCondition(&instance.Status.Conditions, k8sv1beta1.ConditionCRDsUpToDate)
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		setCondition(instance, k8sv1beta1.ConditionCRDsInstalled, metav1.ConditionFalse, reasonCRDsMissing,
+			fmt.Sprintf("The CRDs must be installed by the cluster administrator: %v", strings.Join(missing, ", ")))
+		return false, nil
+	}
+
+	setCondition(instance, k8sv1beta1.ConditionCRDsInstalled, metav1.ConditionTrue, reasonCRDsInstalled, "")
+	return true, nil
+}
+
+// verifyClusterResources checks that the cluster-scoped resources required by the NginxIngressController are installed and
+// reports the outcome in the ClusterResourcesReady condition.
+func (r *NginxIngressControllerReconciler) verifyClusterResources(ctx context.Context, instance *k8sv1beta1.NginxIngressController) (bool, error) {
+	missing, err := r.missingClusterResources(ctx, instance)
+	if err != nil {
+		return false, err
+	}
+
+	if len(missing) > 0 {
+		setCondition(instance, k8sv1beta1.ConditionClusterResourcesReady, metav1.ConditionFalse, reasonClusterResourcesMissing,
+			fmt.Sprintf("The cluster-scoped resources must be installed by the cluster administrator: %v", strings.Join(missing, "; ")))
+		return false, nil
+	}
+
+	setCondition(instance, k8sv1beta1.ConditionClusterResourcesReady, metav1.ConditionTrue, reasonClusterResourcesFound, "")
+	return true, nil
+}
+
+// missingClusterResources returns the descriptions of the cluster-scoped resources required by the NginxIngressController
+// that are not installed.
+func (r *NginxIngressControllerReconciler) missingClusterResources(ctx context.Context, instance *k8sv1beta1.NginxIngressController) ([]string, error) {
+	var missing []string
+
+	rules := rulesForNginxIngressController(instance)
+	if hasNamespacedRBAC(instance) {
+		_, rules = splitClusterScopedRules(rules)
+	}
+	rbacMissing, err := r.missingClusterRBAC(ctx, instance, rules)
+	if err != nil {
+		return nil, err
+	}
+	missing = append(missing, rbacMissing...)
+
+	if hasIngressClassAPI() {
+		name := ingressClassName(instance)
+		err := r.Reader.Get(ctx, types.NamespacedName{Name: name}, &networking.IngressClass{})
+		if err != nil && errors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("IngressClass %v", name))
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting IngressClass %v: %w", name, err)
+		}
+		if instance.Spec.IngressClassParameters != nil {
+			err := r.Reader.Get(ctx, types.NamespacedName{Name: name}, &k8sv1beta1.IngressClassParameters{})
+			if err != nil && errors.IsNotFound(err) {
+				missing = append(missing, fmt.Sprintf("IngressClassParameters %v", name))
+			} else if err != nil {
+				return nil, fmt.Errorf("error getting IngressClassParameters %v: %w", name, err)
+			}
+		}
+	}
+
+	if r.SccAPIExists {
+		found, err := scc.HasServiceAccount(r.Reader, instance.Namespace, instance.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("SecurityContextConstraints nginx-ingress-scc for the ServiceAccount %v/%v", instance.Namespace, instance.Name))
+		}
+	}
+
+	return missing, nil
+}
+
+// missingClusterRBAC returns the descriptions of the ClusterRoleBinding of the NginxIngressController and of the rules of its
+// ClusterRole that are not installed.
+func (r *NginxIngressControllerReconciler) missingClusterRBAC(ctx context.Context, instance *k8sv1beta1.NginxIngressController, rules []rbacv1.PolicyRule) ([]string, error) {
+	name := rbacName(instance)
+	subject := subjectForServiceAccount(instance.Namespace, instance.Name)
+	expected := fmt.Sprintf("ClusterRoleBinding %v for the ServiceAccount %v/%v", name, instance.Namespace, instance.Name)
+
+	crb := &rbacv1.ClusterRoleBinding{}
+	err := r.Reader.Get(ctx, types.NamespacedName{Name: name}, crb)
+	if err != nil && errors.IsNotFound(err) {
+		return []string{expected}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting ClusterRoleBinding %v: %w", name, err)
+	}
+
+	bound := false
+	for _, s := range crb.Subjects {
+		if s.Kind == subject.Kind && s.Name == subject.Name && s.Namespace == subject.Namespace {
+			bound = true
+		}
+	}
+	if !bound || crb.RoleRef.Kind != "ClusterRole" {
+		return []string{expected}, nil
+	}
+
+	cr := &rbacv1.ClusterRole{}
+	err = r.Reader.Get(ctx, types.NamespacedName{Name: crb.RoleRef.Name}, cr)
+	if err != nil && errors.IsNotFound(err) {
+		return []string{fmt.Sprintf("ClusterRole %v", crb.RoleRef.Name)}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting ClusterRole %v: %w", crb.RoleRef.Name, err)
+	}
+
+	if ungranted := ungrantedRules(cr.Rules, rules); len(ungranted) > 0 {
+		return []string{fmt.Sprintf("rules of the ClusterRole %v: %v", cr.Name, strings.Join(ungranted, ", "))}, nil
+	}
+	return nil, nil
+}
+
+// ungrantedRules returns the verbs and resources of the required rules that are not granted by the rules, as
+// "verb group/resource".
+func ungrantedRules(granted []rbacv1.PolicyRule, required []rbacv1.PolicyRule) []string {
+	var result []string
+	for _, rule := range required {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, verb := range rule.Verbs {
+					if !isGranted(granted, verb, group, resource) {
+						result = append(result, fmt.Sprintf("%v %v/%v", verb, group, resource))
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+func isGranted(rules []rbacv1.PolicyRule, verb string, group string, resource string) bool {
+	for _, rule := range rules {
+		if len(rule.ResourceNames) > 0 {
+			continue
+		}
+		if (containsString(rule.Verbs, verb) || containsString(rule.Verbs, rbacv1.VerbAll)) &&
+			(containsString(rule.APIGroups, group) || containsString(rule.APIGroups, rbacv1.APIGroupAll)) &&
+			(containsString(rule.Resources, resource) || containsString(rule.Resources, rbacv1.ResourceAll)) {
+			return true
+		}
+	}
+	return false
+}
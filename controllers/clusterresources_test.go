@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	networking "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUngrantedRules(t *testing.T) {
+	required := []rbacv1.PolicyRule{
+		{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+		{Verbs: []string{"get"}, APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingressclasses"}},
+	}
+
+	tests := []struct {
+		granted  []rbacv1.PolicyRule
+		expected []string
+		msg      string
+	}{
+		{
+			granted:  required,
+			expected: nil,
+			msg:      "same rules",
+		},
+		{
+			granted: []rbacv1.PolicyRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+			},
+			expected: nil,
+			msg:      "wildcard rule",
+		},
+		{
+			granted: []rbacv1.PolicyRule{
+				{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+				{Verbs: []string{"get"}, APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingressclasses"}, ResourceNames: []string{"nginx"}},
+			},
+			expected: []string{"list /secrets", "get networking.k8s.io/ingressclasses"},
+			msg:      "missing verb and rule restricted to resource names",
+		},
+	}
+
+	for _, test := range tests {
+		if diff := cmp.Diff(test.expected, ungrantedRules(test.granted, required)); diff != "" {
+			t.Errorf("ungrantedRules() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestVerifyCRDs(t *testing.T) {
+	s := newCATestScheme(t)
+	if err := apixv1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add the CRD API to the scheme: %v", err)
+	}
+	var crds []client.Object
+	for _, name := range []string{crdVirtualServers, crdVirtualServerRoutes, crdTransportServers} {
+		crds = append(crds, &apixv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(crds...).Build()
+	r := &NginxIngressControllerReconciler{Client: c, Reader: c, Scheme: s, Namespaced: true}
+
+	instance := &k8sv1beta1.NginxIngressController{}
+	setCondition(instance, k8sv1beta1.ConditionCRDsUpToDate, metav1.ConditionTrue, reasonCRDsUpToDate, "")
+
+	ready, err := r.verifyCRDs(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("verifyCRDs() returned unexpected error %v", err)
+	}
+	if ready {
+		t.Errorf("verifyCRDs() returned ready when a CRD is missing")
+	}
+	condition := meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionCRDsInstalled)
+	expectedMessage := "The CRDs must be installed by the cluster administrator: policies.k8s.nginx.org"
+	if condition == nil || condition.Reason != reasonCRDsMissing || condition.Message != expectedMessage {
+		t.Errorf("verifyCRDs() set the condition %+v but expected %v %q", condition, reasonCRDsMissing, expectedMessage)
+	}
+	if meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionCRDsUpToDate) != nil {
+		t.Errorf("verifyCRDs() did not remove the %v condition", k8sv1beta1.ConditionCRDsUpToDate)
+	}
+
+	disabled := false
+	instance.Spec.EnableCRDs = &disabled
+	instance.Spec.EnableTLSPassthrough = true
+	ready, err = r.verifyCRDs(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("verifyCRDs() returned unexpected error %v", err)
+	}
+	if !ready || !meta.IsStatusConditionTrue(instance.Status.Conditions, k8sv1beta1.ConditionCRDsInstalled) {
+		t.Errorf("verifyCRDs() returned not ready when the required CRDs are installed")
+	}
+}
+
+func TestVerifyClusterResources(t *testing.T) {
+	previous := RunningK8sVersion
+	RunningK8sVersion = version.MustParseGeneric("v1.23.0")
+	defer func() { RunningK8sVersion = previous }()
+
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{WatchNamespace: "my-nginx-ingress", IngressClass: "nginx"},
+	}
+	_, clusterRules := splitClusterScopedRules(rulesForNginxIngressController(instance))
+	clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "nginx-ingress"}, Rules: clusterRules}
+	binding := clusterRoleBindingForInstance(instance, clusterRole.Name)
+	ingressClass := ingressClassForNginxIngressController(instance, false, false)
+
+	otherBinding := clusterRoleBindingForInstance(instance, clusterRole.Name)
+	otherBinding.Subjects = []rbacv1.Subject{subjectForServiceAccount("other", "other")}
+
+	tests := []struct {
+		objects         []client.Object
+		expectedMessage string
+		msg             string
+	}{
+		{
+			objects:         []client.Object{clusterRole, binding, ingressClass},
+			expectedMessage: "",
+			msg:             "all the resources installed",
+		},
+		{
+			objects: nil,
+			expectedMessage: "The cluster-scoped resources must be installed by the cluster administrator: " +
+				"ClusterRoleBinding nginx-ingress-my-nginx-ingress-my-nginx-ingress for the ServiceAccount my-nginx-ingress/my-nginx-ingress; " +
+				"IngressClass nginx",
+			msg: "no resources installed",
+		},
+		{
+			objects: []client.Object{clusterRole, otherBinding, ingressClass},
+			expectedMessage: "The cluster-scoped resources must be installed by the cluster administrator: " +
+				"ClusterRoleBinding nginx-ingress-my-nginx-ingress-my-nginx-ingress for the ServiceAccount my-nginx-ingress/my-nginx-ingress",
+			msg: "ClusterRoleBinding for another ServiceAccount",
+		},
+		{
+			objects: []client.Object{&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "nginx-ingress"}}, binding, &networking.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}}},
+			expectedMessage: "The cluster-scoped resources must be installed by the cluster administrator: " +
+				"rules of the ClusterRole nginx-ingress: get networking.k8s.io/ingressclasses",
+			msg: "ClusterRole without the required rules",
+		},
+	}
+
+	for _, test := range tests {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(test.objects...).Build()
+		r := &NginxIngressControllerReconciler{Client: c, Reader: c, Scheme: s, Namespaced: true}
+		instance := instance.DeepCopy()
+
+		ready, err := r.verifyClusterResources(context.Background(), instance)
+		if err != nil {
+			t.Fatalf("verifyClusterResources() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		if ready != (test.expectedMessage == "") {
+			t.Errorf("verifyClusterResources() returned %v for the case of %v", ready, test.msg)
+		}
+		condition := meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionClusterResourcesReady)
+		if condition == nil || condition.Message != test.expectedMessage {
+			t.Errorf("verifyClusterResources() set the condition %+v but expected the message %q for the case of %v", condition, test.expectedMessage, test.msg)
+		}
+	}
+}
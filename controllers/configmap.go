@@ -1,16 +1,15 @@
 package controllers
 
 import (
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	v1 "k8s.io/api/core/v1"
 )
 
-func configMapForNginxIngressController(instance *k8sv1alpha1.NginxIngressController, scheme *runtime.Scheme) (*v1.ConfigMap, error) {
+func configMapForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme) (*v1.ConfigMap, error) {
 	cm := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      instance.Name,
@@ -23,10 +22,3 @@ func configMapForNginxIngressController(instance *k8sv1alpha1.NginxIngressContro
 	}
 	return cm, nil
 }
-
-func configMapMutateFn(cm *v1.ConfigMap, configMapData map[string]string) controllerutil.MutateFn {
-	return func() error {
-		cm.Data = configMapData
-		return nil
-	}
-}
@@ -3,46 +3,76 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"os"
 
 	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"github.com/nginxinc/nginx-ingress-operator/config/crd/kic"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
-	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const decoderBufferSize = 100
+
+// Names of the KIC CRDs.
 const (
-	crdsPath          = "./config/crd/kic"
-	decoderBufferSize = 100
+	crdVirtualServers        = "virtualservers.k8s.nginx.org"
+	crdVirtualServerRoutes   = "virtualserverroutes.k8s.nginx.org"
+	crdTransportServers      = "transportservers.k8s.nginx.org"
+	crdPolicies              = "policies.k8s.nginx.org"
+	crdGlobalConfigurations  = "globalconfigurations.k8s.nginx.org"
+	crdAPLogConfs            = "aplogconfs.appprotect.f5.com"
+	crdAPPolicies            = "appolicies.appprotect.f5.com"
+	crdAPUserSigs            = "apusersigs.appprotect.f5.com"
+	crdAPDosLogConfs         = "apdoslogconfs.appprotectdos.f5.com"
+	crdAPDosPolicies         = "apdospolicies.appprotectdos.f5.com"
+	crdDosProtectedResources = "dosprotectedresources.appprotectdos.f5.com"
 )
 
-func getCRDsManifests() ([]string, error) {
-	files, err := ioutil.ReadDir(crdsPath)
+// managedByLabel marks the CRDs installed by the Operator. Only those CRDs are pruned.
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "nginx-ingress-operator"
+)
+
+// crdsFS returns the manifests of the KIC CRDs: the manifests in crdsDir when it is set, and the manifests embedded in the
+// Operator binary otherwise.
+func crdsFS(crdsDir string) fs.FS {
+	if crdsDir != "" {
+		return os.DirFS(crdsDir)
+	}
+	return kic.CRDs
+}
+
+func getCRDsManifests(crds fs.FS) ([]string, error) {
+	files, err := fs.Glob(crds, "*.yaml")
 	if err != nil {
 		return nil, err
 	}
-
-	var manifests []string
-	for _, f := range files {
-		manifests = append(manifests, fmt.Sprintf("%v/%v", crdsPath, f.Name()))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no CRD manifests found")
 	}
 
-	return manifests, nil
+	return files, nil
 }
 
-func kicCRDs() ([]*v1.CustomResourceDefinition, error) {
-	manifests, err := getCRDsManifests()
+func kicCRDs(crdsFS fs.FS) ([]*v1.CustomResourceDefinition, error) {
+	manifests, err := getCRDsManifests(crdsFS)
 	if err != nil {
 		return nil, err
 	}
 
 	var crds []*v1.CustomResourceDefinition
 	for _, path := range manifests {
-		f, err := os.Open(path)
+		f, err := crdsFS.Open(path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open the CRD manifest %v: %w", path, err)
 		}
@@ -66,42 +96,225 @@ func kicCRDs() ([]*v1.CustomResourceDefinition, error) {
 	return crds, nil
 }
 
-func createKICCustomResourceDefinitions(log logr.Logger, mgr manager.Manager) error {
-	// Create CRDs with a different client (apiextensions)
-	apixClient, err := apixv1client.NewForConfig(mgr.GetConfig())
+// crdNamesForNginxIngressController returns the names of the CRDs watched by the Ingress Controller of the NginxIngressController.
+func crdNamesForNginxIngressController(instance *k8sv1beta1.NginxIngressController) []string {
+	var names []string
+	if instance.Spec.EnableCRDs == nil || *instance.Spec.EnableCRDs {
+		names = append(names, crdVirtualServers, crdVirtualServerRoutes, crdTransportServers, crdPolicies)
+	}
+	if instance.Spec.EnableTLSPassthrough {
+		names = append(names, crdTransportServers)
+	}
+	if globalConfigurationForNginxIngressController(instance) != nil {
+		names = append(names, crdGlobalConfigurations)
+	}
+	if instance.Spec.AppProtect != nil && instance.Spec.AppProtect.Enable {
+		names = append(names, crdAPLogConfs, crdAPPolicies, crdAPUserSigs)
+	}
+	if instance.Spec.AppProtectDos != nil && instance.Spec.AppProtectDos.Enable {
+		names = append(names, crdAPDosLogConfs, crdAPDosPolicies, crdDosProtectedResources)
+	}
+	return names
+}
+
+// requiredCRDs returns the names of the CRDs watched by any of the NginxIngressController resources that are not being deleted.
+func requiredCRDs(instances []k8sv1beta1.NginxIngressController) map[string]bool {
+	required := map[string]bool{}
+	for i := range instances {
+		if instances[i].DeletionTimestamp != nil {
+			continue
+		}
+		for _, name := range crdNamesForNginxIngressController(&instances[i]) {
+			required[name] = true
+		}
+	}
+	return required
+}
+
+// partitionCRDs splits the CRDs into the required CRDs and the CRDs no NginxIngressController needs.
+func partitionCRDs(crds []*v1.CustomResourceDefinition, required map[string]bool) (used []*v1.CustomResourceDefinition, unused []*v1.CustomResourceDefinition) {
+	for _, crd := range crds {
+		if required[crd.Name] {
+			used = append(used, crd)
+		} else {
+			unused = append(unused, crd)
+		}
+	}
+	return used, unused
+}
+
+// reconcileKICCustomResourceDefinitions installs the KIC CRDs required by the features enabled in the NginxIngressController
+// resources, and removes the CRDs no longer required when PruneCRDs is set. It returns the names of the installed CRDs and the
+// decisions on the updates of the CRDs with breaking changes.
+func (r *SharedResourcesReconciler) reconcileKICCustomResourceDefinitions(ctx context.Context, log logr.Logger) (map[string]bool, []crdUpdate, error) {
+	list := &k8sv1beta1.NginxIngressControllerList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, nil, fmt.Errorf("error listing NginxIngressControllers: %w", err)
+	}
+
+	crds, err := kicCRDs(crdsFS(r.CRDsDir))
 	if err != nil {
-		log.Error(err, "unable to create client for CRD registration")
-		return err
+		return nil, nil, err
 	}
+	used, unused := partitionCRDs(crds, requiredCRDs(list.Items))
 
-	crds, err := kicCRDs()
+	updates, err := createKICCustomResourceDefinitions(ctx, log, r.CRDClient, used)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	if r.PruneCRDs {
+		if err := r.pruneKICCustomResourceDefinitions(ctx, log, unused); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	installed := map[string]bool{}
+	for _, crd := range used {
+		installed[crd.Name] = true
+	}
+	return installed, updates, nil
+}
 
-	crdsClient := apixClient.CustomResourceDefinitions()
+// createKICCustomResourceDefinitions creates the CRDs and updates the existing CRDs that differ from the manifests. An update with
+// breaking changes is only applied when the existing CRD has the allowBreakingUpdateAnnotation. It returns the decisions on the
+// updates with breaking changes.
+func createKICCustomResourceDefinitions(ctx context.Context, log logr.Logger, crdsClient apixv1client.CustomResourceDefinitionInterface, crds []*v1.CustomResourceDefinition) ([]crdUpdate, error) {
+	var updates []crdUpdate
 	for _, crd := range crds {
-		oldCRD, err := crdsClient.Get(context.TODO(), crd.Name, metav1.GetOptions{})
+		hash, err := contentHash(crd.Spec)
+		if err != nil {
+			return nil, err
+		}
+
+		oldCRD, err := crdsClient.Get(ctx, crd.Name, metav1.GetOptions{})
 		if err != nil {
 			if errors.IsNotFound(err) {
 				log.V(1).Info(fmt.Sprintf("no previous CRD %v found, creating a new one.", crd.Name))
-				_, err = crdsClient.Create(context.TODO(), crd, metav1.CreateOptions{})
+				setManagedByLabel(crd)
+				setContentHash(crd, hash)
+				_, err = crdsClient.Create(ctx, crd, metav1.CreateOptions{FieldManager: fieldManager})
 				if err != nil {
-					return fmt.Errorf("error creating CRD %v: %w", crd.Name, err)
+					return nil, fmt.Errorf("error creating CRD %v: %w", crd.Name, err)
 				}
 			} else {
-				return fmt.Errorf("error getting CRD %v: %w", crd.Name, err)
+				return nil, fmt.Errorf("error getting CRD %v: %w", crd.Name, err)
+			}
+			continue
+		}
+
+		// Earlier versions of the Operator installed the CRDs without the managedByLabel, so the CRDs they created are adopted.
+		// The CRDs installed by other means, like the Helm chart of the Ingress Controller, are updated but never labeled.
+		adopt := oldCRD.Labels[managedByLabel] != managedByValue && isInstalledByLegacyOperator(oldCRD)
+
+		// The API server sets the defaults of the fields that are not in the manifest, so only the fields of the manifest are compared.
+		if isContentUpToDate(oldCRD, hash, equality.Semantic.DeepDerivative(crd.Spec, oldCRD.Spec)) {
+			if adopt {
+				log.V(1).Info(fmt.Sprintf("previous CRD %v installed by an earlier version of the Operator found, adopting it.", crd.Name))
+				setManagedByLabel(oldCRD)
+				if _, err = crdsClient.Update(ctx, oldCRD, metav1.UpdateOptions{FieldManager: fieldManager}); err != nil {
+					return nil, fmt.Errorf("error updating CRD %v: %w", crd.Name, err)
+				}
+			}
+			continue
+		}
+
+		// Update CRDs if they already exist and the update doesn't break the existing custom resources
+		if changes := breakingCRDChanges(oldCRD, crd); len(changes) > 0 {
+			update := crdUpdate{name: crd.Name, changes: changes, allowed: oldCRD.Annotations[allowBreakingUpdateAnnotation] == "true"}
+			updates = append(updates, update)
+			if !update.allowed {
+				log.Info(fmt.Sprintf("previous CRD %v found, refusing to update it with breaking changes.", crd.Name), "changes", changes)
+				continue
 			}
+			log.Info(fmt.Sprintf("previous CRD %v found, updating it with breaking changes.", crd.Name), "changes", changes)
+			delete(oldCRD.Annotations, allowBreakingUpdateAnnotation)
 		} else {
-			// Update CRDs if they already exist
 			log.V(1).Info(fmt.Sprintf("previous CRD %v found, updating.", crd.Name))
-			oldCRD.Spec = crd.Spec
-			_, err = crdsClient.Update(context.TODO(), oldCRD, metav1.UpdateOptions{})
-			if err != nil {
-				return fmt.Errorf("error updating CRD %v: %w", crd.Name, err)
-			}
+		}
+		oldCRD.Spec = crd.Spec
+		if adopt {
+			setManagedByLabel(oldCRD)
+		}
+		setContentHash(oldCRD, hash)
+		_, err = crdsClient.Update(ctx, oldCRD, metav1.UpdateOptions{FieldManager: fieldManager})
+		if err != nil {
+			return nil, fmt.Errorf("error updating CRD %v: %w", crd.Name, err)
+		}
+	}
+
+	return updates, nil
+}
+
+// isInstalledByLegacyOperator returns whether the CRD was installed by an earlier version of the Operator: the legacy field
+// managers are its only field managers, apart from the API server updating its status. The Operator now sends its requests
+// with its own field manager, so the CRDs it updated without installing them are not mistaken for its own.
+func isInstalledByLegacyOperator(crd *v1.CustomResourceDefinition) bool {
+	found := false
+	for _, entry := range crd.ManagedFields {
+		if entry.Subresource == "status" {
+			continue
+		}
+		if !legacyFieldManagers[entry.Manager] {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func setManagedByLabel(crd *v1.CustomResourceDefinition) {
+	if crd.Labels == nil {
+		crd.Labels = map[string]string{}
+	}
+	crd.Labels[managedByLabel] = managedByValue
+}
+
+// pruneKICCustomResourceDefinitions deletes the CRDs installed by the Operator that no NginxIngressController needs.
+// A CRD is kept while custom resources of its kind exist, so removing a feature from a NginxIngressController never deletes
+// the resources of the users.
+func (r *SharedResourcesReconciler) pruneKICCustomResourceDefinitions(ctx context.Context, log logr.Logger, crds []*v1.CustomResourceDefinition) error {
+	for _, crd := range crds {
+		existing, err := r.CRDClient.Get(ctx, crd.Name, metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("error getting CRD %v: %w", crd.Name, err)
+		}
+		if existing.Labels[managedByLabel] != managedByValue {
+			continue
+		}
+
+		inUse, err := r.customResourcesExist(ctx, existing)
+		if err != nil {
+			return fmt.Errorf("error listing the custom resources of CRD %v: %w", crd.Name, err)
+		}
+		if inUse {
+			log.V(1).Info(fmt.Sprintf("CRD %v is not required but custom resources of its kind exist, keeping it.", crd.Name))
+			continue
+		}
+
+		log.Info(fmt.Sprintf("CRD %v is not required by any NginxIngressController, deleting it.", crd.Name))
+		err = r.CRDClient.Delete(ctx, crd.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting CRD %v: %w", crd.Name, err)
 		}
 	}
 
 	return nil
 }
+
+// customResourcesExist returns whether any custom resource of the kind of the CRD exists in the cluster.
+func (r *SharedResourcesReconciler) customResourcesExist(ctx context.Context, crd *v1.CustomResourceDefinition) (bool, error) {
+	for _, version := range crd.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.ListKind})
+		if err := r.List(ctx, list, client.Limit(1)); err != nil {
+			return false, err
+		}
+		return len(list.Items) > 0, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"github.com/nginxinc/nginx-ingress-operator/config/crd/kic"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKICCRDs(t *testing.T) {
+	manifests, err := getCRDsManifests(kic.CRDs)
+	if err != nil {
+		t.Fatalf("getCRDsManifests() returned unexpected error %v", err)
+	}
+
+	crds, err := kicCRDs(kic.CRDs)
+	if err != nil {
+		t.Fatalf("kicCRDs() returned unexpected error %v", err)
+	}
+	if len(crds) != len(manifests) {
+		t.Fatalf("kicCRDs() returned %v CRDs but expected %v", len(crds), len(manifests))
+	}
+
+	for i, crd := range crds {
+		if crd.Kind != "CustomResourceDefinition" {
+			t.Errorf("kicCRDs() returned kind %q but expected CustomResourceDefinition for the manifest %v", crd.Kind, manifests[i])
+		}
+		expectedName := crd.Spec.Names.Plural + "." + crd.Spec.Group
+		if crd.Name != expectedName {
+			t.Errorf("kicCRDs() returned the CRD %q but expected %q for the manifest %v", crd.Name, expectedName, manifests[i])
+		}
+		if manifests[i] != crd.Spec.Group+"_"+crd.Spec.Names.Plural+".yaml" {
+			t.Errorf("kicCRDs() returned the CRD %v from the manifest %v with an unexpected file name", crd.Name, manifests[i])
+		}
+		if len(crd.Spec.Versions) == 0 {
+			t.Errorf("kicCRDs() returned the CRD %v without versions", crd.Name)
+		}
+
+		storage := 0
+		for _, v := range crd.Spec.Versions {
+			if v.Storage {
+				storage++
+			}
+			if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				t.Errorf("kicCRDs() returned the version %v of the CRD %v without a schema", v.Name, crd.Name)
+			}
+		}
+		if storage != 1 {
+			t.Errorf("kicCRDs() returned the CRD %v with %v storage versions but expected 1", crd.Name, storage)
+		}
+	}
+}
+
+func TestKICCRDsFromDirectory(t *testing.T) {
+	tests := []struct {
+		crds        fstest.MapFS
+		expectedErr bool
+		msg         string
+	}{
+		{
+			crds: fstest.MapFS{
+				"example.com_foos.yaml": {Data: []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: foos.example.com\n")},
+				"README.md":             {Data: []byte("not a manifest")},
+			},
+			expectedErr: false,
+			msg:         "directory with a manifest and other files",
+		},
+		{
+			crds:        fstest.MapFS{},
+			expectedErr: true,
+			msg:         "empty directory",
+		},
+		{
+			crds: fstest.MapFS{
+				"example.com_foos.yaml": {Data: []byte("kind: [")},
+			},
+			expectedErr: true,
+			msg:         "invalid manifest",
+		},
+	}
+
+	for _, test := range tests {
+		crds, err := kicCRDs(test.crds)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("kicCRDs() returned error %v but expected error %v for the case of %v", err, test.expectedErr, test.msg)
+		}
+		if err == nil && (len(crds) != 1 || crds[0].Name != "foos.example.com") {
+			t.Errorf("kicCRDs() returned %v CRDs but expected foos.example.com for the case of %v", len(crds), test.msg)
+		}
+	}
+}
+
+func TestRequiredCRDs(t *testing.T) {
+	disabled := false
+	deleted := metav1.Now()
+
+	tests := []struct {
+		instances []k8sv1beta1.NginxIngressController
+		expected  map[string]bool
+		msg       string
+	}{
+		{
+			instances: nil,
+			expected:  map[string]bool{},
+			msg:       "no NginxIngressControllers",
+		},
+		{
+			instances: []k8sv1beta1.NginxIngressController{{}},
+			expected: map[string]bool{
+				crdVirtualServers:      true,
+				crdVirtualServerRoutes: true,
+				crdTransportServers:    true,
+				crdPolicies:            true,
+			},
+			msg: "default NginxIngressController",
+		},
+		{
+			instances: []k8sv1beta1.NginxIngressController{
+				{Spec: k8sv1beta1.NginxIngressControllerSpec{EnableCRDs: &disabled}},
+			},
+			expected: map[string]bool{},
+			msg:      "CRDs disabled",
+		},
+		{
+			instances: []k8sv1beta1.NginxIngressController{
+				{Spec: k8sv1beta1.NginxIngressControllerSpec{EnableCRDs: &disabled}},
+				{Spec: k8sv1beta1.NginxIngressControllerSpec{
+					EnableCRDs:          &disabled,
+					GlobalConfiguration: &k8sv1beta1.ObjectReference{Name: "gc", Namespace: "default"},
+					AppProtect:          &k8sv1beta1.AppProtect{Enable: true},
+				}},
+				{Spec: k8sv1beta1.NginxIngressControllerSpec{
+					EnableCRDs:           &disabled,
+					EnableTLSPassthrough: true,
+					AppProtectDos:        &k8sv1beta1.AppProtectDos{Enable: true},
+				}},
+			},
+			expected: map[string]bool{
+				crdTransportServers:      true,
+				crdGlobalConfigurations:  true,
+				crdAPLogConfs:            true,
+				crdAPPolicies:            true,
+				crdAPUserSigs:            true,
+				crdAPDosLogConfs:         true,
+				crdAPDosPolicies:         true,
+				crdDosProtectedResources: true,
+			},
+			msg: "union of the features of several NginxIngressControllers",
+		},
+		{
+			instances: []k8sv1beta1.NginxIngressController{
+				{Spec: k8sv1beta1.NginxIngressControllerSpec{
+					EnableCRDs: &disabled,
+					IngressClassParameters: &k8sv1beta1.IngressClassParametersSpec{
+						GlobalConfiguration: &k8sv1beta1.ObjectReference{Name: "gc"},
+					},
+				}},
+			},
+			expected: map[string]bool{crdGlobalConfigurations: true},
+			msg:      "GlobalConfiguration of the ingressClassParameters",
+		},
+		{
+			instances: []k8sv1beta1.NginxIngressController{
+				{Spec: k8sv1beta1.NginxIngressControllerSpec{EnableCRDs: &disabled}},
+				{
+					ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+					Spec:       k8sv1beta1.NginxIngressControllerSpec{AppProtect: &k8sv1beta1.AppProtect{Enable: true}},
+				},
+			},
+			expected: map[string]bool{},
+			msg:      "NginxIngressController being deleted",
+		},
+	}
+
+	for _, test := range tests {
+		result := requiredCRDs(test.instances)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("requiredCRDs() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestPartitionEmbeddedCRDs(t *testing.T) {
+	crds, err := kicCRDs(kic.CRDs)
+	if err != nil {
+		t.Fatalf("kicCRDs() returned unexpected error %v", err)
+	}
+
+	all := []k8sv1beta1.NginxIngressController{{Spec: k8sv1beta1.NginxIngressControllerSpec{
+		EnableTLSPassthrough: true,
+		GlobalConfiguration:  &k8sv1beta1.ObjectReference{Name: "gc", Namespace: "default"},
+		AppProtect:           &k8sv1beta1.AppProtect{Enable: true},
+		AppProtectDos:        &k8sv1beta1.AppProtectDos{Enable: true},
+	}}}
+	used, unused := partitionCRDs(crds, requiredCRDs(all))
+	if len(unused) != 0 {
+		t.Errorf("partitionCRDs() returned CRDs not required by any feature: %v", crdNames(unused))
+	}
+	if len(used) != len(crds) {
+		t.Errorf("partitionCRDs() returned %v required CRDs but expected %v", len(used), len(crds))
+	}
+
+	used, unused = partitionCRDs(crds, requiredCRDs(nil))
+	if len(used) != 0 || len(unused) != len(crds) {
+		t.Errorf("partitionCRDs() returned the required CRDs %v but expected none without NginxIngressControllers", crdNames(used))
+	}
+}
+
+func crdNames(crds []*v1.CustomResourceDefinition) []string {
+	var names []string
+	for _, crd := range crds {
+		names = append(names, crd.Name)
+	}
+	return names
+}
+
+func TestCreateKICCustomResourceDefinitionsAdoptsUnlabeledCRDs(t *testing.T) {
+	crds, err := kicCRDs(kic.CRDs)
+	if err != nil {
+		t.Fatalf("kicCRDs() returned unexpected error %v", err)
+	}
+	upToDate, outdated, foreign := crds[0], crds[1], crds[2]
+
+	legacy := []metav1.ManagedFieldsEntry{
+		{Manager: "manager", Operation: metav1.ManagedFieldsOperationUpdate},
+		{Manager: "kube-apiserver", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status"},
+	}
+
+	// CRDs installed by an earlier version of the Operator: one with the current content and one with an earlier content.
+	existingUpToDate := upToDate.DeepCopy()
+	existingUpToDate.ManagedFields = legacy
+	hash, err := contentHash(existingUpToDate.Spec)
+	if err != nil {
+		t.Fatalf("contentHash() returned unexpected error %v", err)
+	}
+	setContentHash(existingUpToDate, hash)
+	existingOutdated := outdated.DeepCopy()
+	existingOutdated.ManagedFields = legacy
+	setContentHash(existingOutdated, "previous")
+	// A CRD installed by the Helm chart of the Ingress Controller and updated by an earlier version of the Operator.
+	existingForeign := foreign.DeepCopy()
+	existingForeign.ManagedFields = append([]metav1.ManagedFieldsEntry{{Manager: "helm", Operation: metav1.ManagedFieldsOperationUpdate}}, legacy...)
+	setContentHash(existingForeign, "previous")
+
+	crdsClient := apixfake.NewSimpleClientset(existingUpToDate, existingOutdated, existingForeign).ApiextensionsV1().CustomResourceDefinitions()
+	ctx := context.Background()
+	all := []*v1.CustomResourceDefinition{upToDate, outdated, foreign}
+	var desired []*v1.CustomResourceDefinition
+	for _, crd := range all {
+		desired = append(desired, crd.DeepCopy())
+	}
+	if _, err := createKICCustomResourceDefinitions(ctx, logr.Discard(), crdsClient, desired); err != nil {
+		t.Fatalf("createKICCustomResourceDefinitions() returned unexpected error %v", err)
+	}
+
+	expected := map[string]bool{upToDate.Name: true, outdated.Name: true, foreign.Name: false}
+	for _, crd := range all {
+		result, err := crdsClient.Get(ctx, crd.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get CRD %v: %v", crd.Name, err)
+		}
+		if labeled := result.Labels[managedByLabel] == managedByValue; labeled != expected[crd.Name] {
+			t.Errorf("createKICCustomResourceDefinitions() returned the CRD %v labeled %v but expected %v", crd.Name, labeled, expected[crd.Name])
+		}
+	}
+
+	r := &SharedResourcesReconciler{Client: fake.NewClientBuilder().Build(), CRDClient: crdsClient}
+	if err := r.pruneKICCustomResourceDefinitions(ctx, logr.Discard(), all); err != nil {
+		t.Fatalf("pruneKICCustomResourceDefinitions() returned unexpected error %v", err)
+	}
+	for _, crd := range all {
+		_, err := crdsClient.Get(ctx, crd.Name, metav1.GetOptions{})
+		if deleted := errors.IsNotFound(err); deleted != expected[crd.Name] {
+			t.Errorf("pruneKICCustomResourceDefinitions() deleted the CRD %v %v but expected %v", crd.Name, deleted, expected[crd.Name])
+		}
+	}
+}
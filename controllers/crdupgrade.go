@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// allowBreakingUpdateAnnotation allows the Operator to update a KIC CRD with breaking changes. It is set on the CRD by the
+// cluster administrator and removed by the Operator once the update is applied, so each breaking update is allowed explicitly.
+const allowBreakingUpdateAnnotation = "k8s.nginx.org/allow-breaking-update"
+
+// Reasons of the CRDsUpToDate condition and of the events about the updates of the CRDs.
+const (
+	reasonCRDsUpToDate     = "CRDsUpToDate"
+	reasonCRDUpdateRefused = "CRDUpdateRefused"
+	reasonCRDUpdateAllowed = "CRDUpdateAllowed"
+)
+
+// crdUpdate is the decision on an update of a CRD with breaking changes.
+type crdUpdate struct {
+	name    string
+	changes []string
+	allowed bool
+}
+
+// breakingCRDChanges returns the changes from the existing CRD to the new CRD that can break the existing custom resources:
+// the removal of a served or stored version and the tightening of the validation of a version.
+func breakingCRDChanges(existing *v1.CustomResourceDefinition, crd *v1.CustomResourceDefinition) []string {
+	versions := map[string]v1.CustomResourceDefinitionVersion{}
+	for _, v := range crd.Spec.Versions {
+		versions[v.Name] = v
+	}
+
+	var changes []string
+	for _, old := range existing.Spec.Versions {
+		v, ok := versions[old.Name]
+		if old.Served && (!ok || !v.Served) {
+			changes = append(changes, fmt.Sprintf("served version %v is removed", old.Name))
+		}
+		if ok && old.Schema != nil && v.Schema != nil {
+			for _, c := range tightenedSchemaChanges("", old.Schema.OpenAPIV3Schema, v.Schema.OpenAPIV3Schema) {
+				changes = append(changes, fmt.Sprintf("version %v: %v", old.Name, c))
+			}
+		}
+	}
+	for _, stored := range existing.Status.StoredVersions {
+		if _, ok := versions[stored]; !ok {
+			changes = append(changes, fmt.Sprintf("stored version %v is removed", stored))
+		}
+	}
+
+	return changes
+}
+
+// tightenedSchemaChanges returns the changes from the old schema to the new schema that reject or prune values accepted by the old
+// schema.
+func tightenedSchemaChanges(path string, old *v1.JSONSchemaProps, schema *v1.JSONSchemaProps) []string {
+	if old == nil || schema == nil {
+		return nil
+	}
+	field := path
+	if field == "" {
+		field = "."
+	}
+
+	var changes []string
+	if schema.Type != "" && old.Type != schema.Type {
+		changes = append(changes, fmt.Sprintf("type of %v changed from %q to %q", field, old.Type, schema.Type))
+	}
+	for _, r := range schema.Required {
+		if !containsString(old.Required, r) {
+			changes = append(changes, fmt.Sprintf("%v.%v is now required", path, r))
+		}
+	}
+	if len(schema.Enum) > 0 {
+		if len(old.Enum) == 0 {
+			changes = append(changes, fmt.Sprintf("values of %v are now restricted", field))
+		}
+		for _, value := range old.Enum {
+			if !containsJSON(schema.Enum, value) {
+				changes = append(changes, fmt.Sprintf("value %v of %v is no longer allowed", string(value.Raw), field))
+			}
+		}
+	}
+	if schema.Pattern != "" && old.Pattern != schema.Pattern {
+		changes = append(changes, fmt.Sprintf("pattern of %v changed to %q", field, schema.Pattern))
+	}
+	if schema.Format != "" && old.Format != schema.Format {
+		changes = append(changes, fmt.Sprintf("format of %v changed to %q", field, schema.Format))
+	}
+	if schema.Maximum != nil && (old.Maximum == nil || *schema.Maximum < *old.Maximum || (schema.ExclusiveMaximum && !old.ExclusiveMaximum)) {
+		changes = append(changes, fmt.Sprintf("maximum of %v is lowered", field))
+	}
+	if schema.Minimum != nil && (old.Minimum == nil || *schema.Minimum > *old.Minimum || (schema.ExclusiveMinimum && !old.ExclusiveMinimum)) {
+		changes = append(changes, fmt.Sprintf("minimum of %v is raised", field))
+	}
+	for _, limit := range []struct {
+		name     string
+		old, new *int64
+		max      bool
+	}{
+		{name: "maxLength", old: old.MaxLength, new: schema.MaxLength, max: true},
+		{name: "maxItems", old: old.MaxItems, new: schema.MaxItems, max: true},
+		{name: "maxProperties", old: old.MaxProperties, new: schema.MaxProperties, max: true},
+		{name: "minLength", old: old.MinLength, new: schema.MinLength},
+		{name: "minItems", old: old.MinItems, new: schema.MinItems},
+		{name: "minProperties", old: old.MinProperties, new: schema.MinProperties},
+	} {
+		if limit.new == nil {
+			continue
+		}
+		if limit.old == nil || (limit.max && *limit.new < *limit.old) || (!limit.max && *limit.new > *limit.old) {
+			changes = append(changes, fmt.Sprintf("%v of %v changed to %v", limit.name, field, *limit.new))
+		}
+	}
+	if old.Nullable && !schema.Nullable {
+		changes = append(changes, fmt.Sprintf("%v is no longer nullable", field))
+	}
+	if preservesUnknownFields(old) && !preservesUnknownFields(schema) {
+		changes = append(changes, fmt.Sprintf("unknown fields of %v are no longer preserved", field))
+	}
+
+	names := make([]string, 0, len(old.Properties))
+	for name := range old.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldProperty := old.Properties[name]
+		property, ok := schema.Properties[name]
+		if !ok {
+			// Fields that are not in the schema are pruned, unless the unknown fields are preserved.
+			if !preservesUnknownFields(schema) {
+				changes = append(changes, fmt.Sprintf("%v.%v is removed", path, name))
+			}
+			continue
+		}
+		changes = append(changes, tightenedSchemaChanges(path+"."+name, &oldProperty, &property)...)
+	}
+
+	if old.Items != nil && schema.Items != nil {
+		changes = append(changes, tightenedSchemaChanges(path+"[*]", old.Items.Schema, schema.Items.Schema)...)
+	}
+	if old.AdditionalProperties != nil && schema.AdditionalProperties != nil {
+		changes = append(changes, tightenedSchemaChanges(path+"[*]", old.AdditionalProperties.Schema, schema.AdditionalProperties.Schema)...)
+	}
+
+	return changes
+}
+
+func preservesUnknownFields(schema *v1.JSONSchemaProps) bool {
+	return schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsJSON(values []v1.JSON, value v1.JSON) bool {
+	for _, v := range values {
+		if string(v.Raw) == string(value.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCRDUpdates records an event on the CRD for each update of a CRD with breaking changes. A refused update is only
+// recorded when its changes differ from the refused changes already reported in the CRDsUpToDate condition, as the refused
+// updates are attempted again on every reconciliation.
+func (r *SharedResourcesReconciler) recordCRDUpdates(updates []crdUpdate, reported map[string]string) {
+	for _, u := range updates {
+		crd := &v1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: u.name}}
+		changes := strings.Join(u.changes, "; ")
+		if u.allowed {
+			r.Recorder.Eventf(crd, corev1.EventTypeWarning, reasonCRDUpdateAllowed,
+				"Updated CRD %v with breaking changes allowed by the %v annotation: %v", u.name, allowBreakingUpdateAnnotation, changes)
+			continue
+		}
+		if reported[u.name] == changes {
+			continue
+		}
+		r.Recorder.Eventf(crd, corev1.EventTypeWarning, reasonCRDUpdateRefused,
+			"Refused to update CRD %v with breaking changes: %v. Annotate the CRD with %v=true to allow the update",
+			u.name, changes, allowBreakingUpdateAnnotation)
+	}
+}
+
+// setCRDsUpToDateCondition reports in the CRDsUpToDate condition whether the update of CRDs was refused.
+func setCRDsUpToDateCondition(instance *k8sv1beta1.NginxIngressController, refused []string) {
+	if len(refused) > 0 {
+		setCondition(instance, k8sv1beta1.ConditionCRDsUpToDate, metav1.ConditionFalse, reasonCRDUpdateRefused,
+			fmt.Sprintf("The update of the CRDs %v was refused because of breaking changes", strings.Join(refused, ", ")))
+		return
+	}
+	setCondition(instance, k8sv1beta1.ConditionCRDsUpToDate, metav1.ConditionTrue, reasonCRDsUpToDate, "")
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"github.com/nginxinc/nginx-ingress-operator/config/crd/kic"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func newTestCRD(schema v1.JSONSchemaProps, versions ...string) *v1.CustomResourceDefinition {
+	crd := &v1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "virtualservers.k8s.nginx.org"}}
+	for i, name := range versions {
+		s := schema.DeepCopy()
+		crd.Spec.Versions = append(crd.Spec.Versions, v1.CustomResourceDefinitionVersion{
+			Name:    name,
+			Served:  true,
+			Storage: i == len(versions)-1,
+			Schema:  &v1.CustomResourceValidation{OpenAPIV3Schema: s},
+		})
+	}
+	return crd
+}
+
+func TestBreakingCRDChanges(t *testing.T) {
+	maxLength := int64(10)
+	shorterMaxLength := int64(5)
+	preserve := true
+
+	schema := v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]v1.JSONSchemaProps{
+					"host":     {Type: "string", MaxLength: &maxLength},
+					"protocol": {Type: "string", Enum: []v1.JSON{{Raw: []byte(`"http"`)}, {Raw: []byte(`"https"`)}}},
+					"upstreams": {
+						Type:  "array",
+						Items: &v1.JSONSchemaPropsOrArray{Schema: &v1.JSONSchemaProps{Type: "object", Properties: map[string]v1.JSONSchemaProps{"port": {Type: "integer"}}}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		existing *v1.CustomResourceDefinition
+		crd      func() *v1.CustomResourceDefinition
+		expected []string
+		msg      string
+	}{
+		{
+			existing: newTestCRD(schema, "v1"),
+			crd:      func() *v1.CustomResourceDefinition { return newTestCRD(schema, "v1") },
+			expected: nil,
+			msg:      "same CRD",
+		},
+		{
+			existing: newTestCRD(schema, "v1"),
+			crd: func() *v1.CustomResourceDefinition {
+				crd := newTestCRD(schema, "v1")
+				spec := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+				spec.Properties["path"] = v1.JSONSchemaProps{Type: "string"}
+				spec.Properties["protocol"] = v1.JSONSchemaProps{Type: "string", Enum: append(spec.Properties["protocol"].Enum, v1.JSON{Raw: []byte(`"grpc"`)})}
+				crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = spec
+				return crd
+			},
+			expected: nil,
+			msg:      "new optional field and enum value",
+		},
+		{
+			existing: newTestCRD(schema, "v1beta1", "v1"),
+			crd: func() *v1.CustomResourceDefinition {
+				crd := newTestCRD(schema, "v1beta1", "v1")
+				crd.Spec.Versions[0].Served = false
+				return crd
+			},
+			expected: []string{"served version v1beta1 is removed"},
+			msg:      "version no longer served",
+		},
+		{
+			existing: func() *v1.CustomResourceDefinition {
+				crd := newTestCRD(schema, "v1beta1", "v1")
+				crd.Status.StoredVersions = []string{"v1beta1", "v1"}
+				return crd
+			}(),
+			crd:      func() *v1.CustomResourceDefinition { return newTestCRD(schema, "v1") },
+			expected: []string{"served version v1beta1 is removed", "stored version v1beta1 is removed"},
+			msg:      "stored version removed",
+		},
+		{
+			existing: newTestCRD(schema, "v1"),
+			crd: func() *v1.CustomResourceDefinition {
+				crd := newTestCRD(schema, "v1")
+				spec := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+				spec.Required = []string{"host"}
+				spec.Properties["host"] = v1.JSONSchemaProps{Type: "string", MaxLength: &shorterMaxLength, Pattern: "^[a-z.]+$"}
+				spec.Properties["protocol"] = v1.JSONSchemaProps{Type: "string", Enum: []v1.JSON{{Raw: []byte(`"https"`)}}}
+				upstreams := spec.Properties["upstreams"]
+				upstreams.Items.Schema.Properties["port"] = v1.JSONSchemaProps{Type: "string"}
+				spec.Properties["upstreams"] = upstreams
+				crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = spec
+				return crd
+			},
+			expected: []string{
+				"version v1: .spec.host is now required",
+				"version v1: pattern of .spec.host changed to \"^[a-z.]+$\"",
+				"version v1: maxLength of .spec.host changed to 5",
+				"version v1: value \"http\" of .spec.protocol is no longer allowed",
+				"version v1: type of .spec.upstreams[*].port changed from \"integer\" to \"string\"",
+			},
+			msg: "tightened validation",
+		},
+		{
+			existing: newTestCRD(schema, "v1"),
+			crd: func() *v1.CustomResourceDefinition {
+				crd := newTestCRD(schema, "v1")
+				delete(crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties, "host")
+				return crd
+			},
+			expected: []string{"version v1: .spec.host is removed"},
+			msg:      "field removed",
+		},
+		{
+			existing: newTestCRD(schema, "v1"),
+			crd: func() *v1.CustomResourceDefinition {
+				crd := newTestCRD(schema, "v1")
+				spec := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+				delete(spec.Properties, "host")
+				spec.XPreserveUnknownFields = &preserve
+				crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = spec
+				return crd
+			},
+			expected: nil,
+			msg:      "field removed from an object that preserves unknown fields",
+		},
+	}
+
+	for _, test := range tests {
+		result := breakingCRDChanges(test.existing, test.crd())
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("breakingCRDChanges() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestBreakingCRDChangesOfEmbeddedCRDs(t *testing.T) {
+	crds, err := kicCRDs(kic.CRDs)
+	if err != nil {
+		t.Fatalf("kicCRDs() returned unexpected error %v", err)
+	}
+
+	for _, crd := range crds {
+		existing := crd.DeepCopy()
+		for _, v := range crd.Spec.Versions {
+			if v.Storage {
+				existing.Status.StoredVersions = []string{v.Name}
+			}
+		}
+		if changes := breakingCRDChanges(existing, crd); len(changes) > 0 {
+			t.Errorf("breakingCRDChanges() returned %v for the unchanged CRD %v", changes, crd.Name)
+		}
+	}
+}
+
+func TestCRDUpdatesReporting(t *testing.T) {
+	tests := []struct {
+		updates        []crdUpdate
+		reported       map[string]string
+		expectedStatus metav1.ConditionStatus
+		expectedEvents []string
+		msg            string
+	}{
+		{
+			updates:        nil,
+			expectedStatus: metav1.ConditionTrue,
+			expectedEvents: nil,
+			msg:            "no breaking changes",
+		},
+		{
+			updates: []crdUpdate{
+				{name: "virtualservers.k8s.nginx.org", changes: []string{"served version v1beta1 is removed"}, allowed: true},
+			},
+			expectedStatus: metav1.ConditionTrue,
+			expectedEvents: []string{
+				"Warning CRDUpdateAllowed Updated CRD virtualservers.k8s.nginx.org with breaking changes allowed by the k8s.nginx.org/allow-breaking-update annotation: served version v1beta1 is removed",
+			},
+			msg: "breaking update allowed",
+		},
+		{
+			updates: []crdUpdate{
+				{name: "policies.k8s.nginx.org", changes: []string{"version v1: .spec.host is now required", "stored version v1alpha1 is removed"}},
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedEvents: []string{
+				"Warning CRDUpdateRefused Refused to update CRD policies.k8s.nginx.org with breaking changes: version v1: .spec.host is now required; " +
+					"stored version v1alpha1 is removed. Annotate the CRD with k8s.nginx.org/allow-breaking-update=true to allow the update",
+			},
+			msg: "breaking update refused",
+		},
+		{
+			updates: []crdUpdate{
+				{name: "policies.k8s.nginx.org", changes: []string{"stored version v1alpha1 is removed"}},
+			},
+			reported:       map[string]string{"policies.k8s.nginx.org": "stored version v1alpha1 is removed"},
+			expectedStatus: metav1.ConditionFalse,
+			expectedEvents: nil,
+			msg:            "breaking update refused again",
+		},
+		{
+			updates: []crdUpdate{
+				{name: "policies.k8s.nginx.org", changes: []string{"version v1: .spec.host is now required", "stored version v1alpha1 is removed"}},
+			},
+			reported:       map[string]string{"policies.k8s.nginx.org": "stored version v1alpha1 is removed"},
+			expectedStatus: metav1.ConditionFalse,
+			expectedEvents: []string{
+				"Warning CRDUpdateRefused Refused to update CRD policies.k8s.nginx.org with breaking changes: version v1: .spec.host is now required; " +
+					"stored version v1alpha1 is removed. Annotate the CRD with k8s.nginx.org/allow-breaking-update=true to allow the update",
+			},
+			msg: "breaking update refused with new changes",
+		},
+	}
+
+	for _, test := range tests {
+		recorder := record.NewFakeRecorder(10)
+		r := &SharedResourcesReconciler{Recorder: recorder}
+		r.recordCRDUpdates(test.updates, test.reported)
+
+		var refused []string
+		for _, u := range test.updates {
+			if !u.allowed {
+				refused = append(refused, u.name)
+			}
+		}
+		instance := &k8sv1beta1.NginxIngressController{}
+		setCRDsUpToDateCondition(instance, refused)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionCRDsUpToDate)
+		if condition == nil || condition.Status != test.expectedStatus {
+			t.Errorf("setCRDsUpToDateCondition() set the condition %v but expected status %v for the case of %v", condition, test.expectedStatus, test.msg)
+		}
+		if diff := cmp.Diff(test.expectedEvents, recordedEvents(recorder)); diff != "" {
+			t.Errorf("recordCRDUpdates() recorded events mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
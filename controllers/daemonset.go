@@ -1,7 +1,7 @@
 package controllers
 
 import (
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -9,7 +9,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func daemonSetForNginxIngressController(instance *k8sv1alpha1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.DaemonSet, error) {
+func daemonSetForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.DaemonSet, error) {
 	runAsUser := new(int64)
 	allowPrivilegeEscalation := new(bool)
 	*runAsUser = 101
@@ -38,16 +38,7 @@ func daemonSetForNginxIngressController(instance *k8sv1alpha1.NginxIngressContro
 							Image:           generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag),
 							ImagePullPolicy: corev1.PullPolicy(instance.Spec.Image.PullPolicy),
 							Args:            generatePodArgs(instance),
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          "http",
-									ContainerPort: 80,
-								},
-								{
-									Name:          "https",
-									ContainerPort: 443,
-								},
-							},
+							Ports:           containerPortsForNginxIngressController(instance),
 							SecurityContext: &corev1.SecurityContext{
 								Capabilities: &corev1.Capabilities{
 									Drop: []corev1.Capability{"ALL"},
@@ -80,28 +71,10 @@ func daemonSetForNginxIngressController(instance *k8sv1alpha1.NginxIngressContro
 			},
 		},
 	}
+	applyProbes(&dep.Spec.Template.Spec.Containers[0], instance)
+	applyPodCustomization(&dep.Spec.Template, instance)
 	if err := ctrl.SetControllerReference(instance, dep, scheme); err != nil {
 		return nil, err
 	}
 	return dep, nil
 }
-
-func hasDaemonSetChanged(ds *appsv1.DaemonSet, instance *k8sv1alpha1.NginxIngressController) bool {
-	// There is only 1 container in our template
-	container := ds.Spec.Template.Spec.Containers[0]
-	if container.Image != generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag) {
-		return true
-	}
-
-	if container.ImagePullPolicy != corev1.PullPolicy(instance.Spec.Image.PullPolicy) {
-		return true
-	}
-
-	return hasDifferentArguments(container, instance)
-}
-
-func updateDaemonSet(ds *appsv1.DaemonSet, instance *k8sv1alpha1.NginxIngressController) *appsv1.DaemonSet {
-	ds.Spec.Template.Spec.Containers[0].Image = generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag)
-	ds.Spec.Template.Spec.Containers[0].Args = generatePodArgs(instance)
-	return ds
-}
@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,21 +16,21 @@ func TestDaemonSetForNginxIngressController(t *testing.T) {
 	boolPointer := func(b bool) *bool { return &b }
 	s := scheme.Scheme
 
-	if err := k8sv1alpha1.AddToScheme(s); err != nil {
-		t.Fatalf("Unable to add k8sv1alpha1 scheme: (%v)", err)
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
 	}
 	runAsUser := new(int64)
 	allowPrivilegeEscalation := new(bool)
 	*runAsUser = 101
 	*allowPrivilegeEscalation = true
 
-	instance := &k8sv1alpha1.NginxIngressController{
+	instance := &k8sv1beta1.NginxIngressController{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "my-nginx-ingress-controller",
 			Namespace: "my-nginx-ingress-controller",
 		},
-		Spec: k8sv1alpha1.NginxIngressControllerSpec{
-			Image: k8sv1alpha1.Image{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			Image: k8sv1beta1.Image{
 				Repository: "nginx-ingress",
 				Tag:        "edge",
 			},
@@ -42,7 +42,7 @@ func TestDaemonSetForNginxIngressController(t *testing.T) {
 			Namespace: "my-nginx-ingress-controller",
 			OwnerReferences: []v1.OwnerReference{
 				{
-					APIVersion:         "k8s.nginx.org/v1alpha1",
+					APIVersion:         "k8s.nginx.org/v1beta1",
 					Name:               instance.Name,
 					Kind:               "NginxIngressController",
 					Controller:         boolPointer(true),
@@ -76,7 +76,14 @@ func TestDaemonSetForNginxIngressController(t *testing.T) {
 									Name:          "https",
 									ContainerPort: 443,
 								},
+								{
+									Name:          "readiness-port",
+									ContainerPort: 8081,
+								},
 							},
+							ReadinessProbe: generateProbe(defaultReadinessProbe, nil),
+							LivenessProbe:  generateProbe(defaultLivenessProbe, nil),
+							StartupProbe:   generateProbe(defaultStartupProbe, nil),
 							SecurityContext: &corev1.SecurityContext{
 								Capabilities: &corev1.Capabilities{
 									Drop: []corev1.Capability{"ALL"},
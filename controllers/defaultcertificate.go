@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func defaultCertificateRenewBeforeFor(instance *k8sv1beta1.NginxIngressController) time.Duration {
+	dc := instance.Spec.DefaultCertificate
+	if dc == nil || dc.RenewBefore == nil {
+		return k8sv1beta1.DefaultCertificateRenewBefore
+	}
+	return dc.RenewBefore.Duration
+}
+
+// hasCertificateParamsChanged returns whether the certificate of the Secret was generated with different parameters.
+// A Secret without the hash annotation was generated with the parameters of the default certificate before they were configurable.
+func hasCertificateParamsChanged(secret *corev1.Secret, params certificateParams) bool {
+	hash, ok := secret.Annotations[defaultCertificateHashAnnotation]
+	if !ok {
+		hash = defaultCertificateParams(&k8sv1beta1.NginxIngressController{}).hash()
+	}
+	return hash != params.hash()
+}
+
+// defaultCertificateRenewal returns the time to wait before the default certificate that expires at notAfter must be regenerated.
+// It returns zero if the certificate must be regenerated now.
+func defaultCertificateRenewal(notAfter time.Time, renewBefore time.Duration, now time.Time) time.Duration {
+	renewAt := notAfter.Add(-renewBefore)
+	if !now.Before(renewAt) {
+		return 0
+	}
+	return renewAt.Sub(now)
+}
+
+// certificateSecret is a TLS Secret with a certificate generated by the Operator.
+type certificateSecret struct {
+	name        string
+	params      certificateParams
+	renewBefore time.Duration
+	// ca signs the certificate. The certificate is self-signed when ca is nil.
+	ca *caKeyPair
+	// description describes the certificate in logs and events.
+	description string
+	// renewedReason is the reason of the event recorded when the certificate is regenerated.
+	renewedReason string
+}
+
+// renewal returns the time to wait before the certificate of the Secret that expires at notAfter must be regenerated. A certificate
+// capped at the expiry of the CA would be capped again, so it is regenerated when the CA is rotated instead of renewBefore its expiry.
+func (cs certificateSecret) renewal(notAfter time.Time, now time.Time) time.Duration {
+	if cs.ca != nil && !notAfter.Before(cs.ca.cert.NotAfter) {
+		return defaultCertificateRenewal(cs.ca.cert.NotAfter, caRenewBefore, now)
+	}
+	return defaultCertificateRenewal(notAfter, cs.renewBefore, now)
+}
+
+// reconcileCertificateSecret creates the Secret with the certificate and regenerates the certificate when it is about to expire or
+// its parameters change. A Secret with the same name that is not controlled by the NginxIngressController is used as is.
+// It returns the expiry time of the certificate, zero if the certificate cannot be read, and whether the Secret was created.
+func (r *NginxIngressControllerReconciler) reconcileCertificateSecret(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController, cs certificateSecret) (time.Time, bool, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Name: cs.name, Namespace: instance.Namespace}, secret)
+	if err != nil && errors.IsNotFound(err) {
+		secret, err = certificateSecretForNginxIngressController(instance, r.Scheme, cs.name, cs.params, cs.ca)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return time.Time{}, false, err
+		}
+		notAfter, err := certificateNotAfter(secret.Data[corev1.TLSCertKey])
+		return notAfter, true, err
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+
+	notAfter, err := certificateNotAfter(secret.Data[corev1.TLSCertKey])
+	needsRenewal := err != nil || cs.renewal(notAfter, time.Now()) == 0
+	paramsChanged := hasCertificateParamsChanged(secret, cs.params.issuedBy(cs.ca))
+
+	// Only the Secret generated by the Operator is regenerated. A Secret with the same name created by the user is used as is.
+	if (needsRenewal || paramsChanged) && metav1.IsControlledBy(secret, instance) {
+		renewed, err := certificateSecretForNginxIngressController(instance, r.Scheme, cs.name, cs.params, cs.ca)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		secret.Data = renewed.Data
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[defaultCertificateHashAnnotation] = renewed.Annotations[defaultCertificateHashAnnotation]
+		if err := r.Update(ctx, secret); err != nil {
+			return time.Time{}, false, err
+		}
+
+		notAfter, err = certificateNotAfter(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		msg := fmt.Sprintf("Renewed the %v", cs.description)
+		if paramsChanged {
+			msg = fmt.Sprintf("Regenerated the %v after its configuration changed", cs.description)
+		}
+		log.Info(msg, "Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, cs.renewedReason,
+			"%v in Secret %v, valid until %v", msg, secret.Name, notAfter.Format(time.RFC3339))
+	} else if err != nil {
+		log.Info(fmt.Sprintf("The %v cannot be read and the Secret is not managed by the Operator", cs.description),
+			"Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name, "error", err.Error())
+		return time.Time{}, false, nil
+	}
+
+	return notAfter, false, nil
+}
+
+// reconcileDefaultSecret creates the Secret with the certificate of the default server when defaultSecret is not set,
+// and regenerates the certificate when it is about to expire or defaultCertificate changes. The certificate is issued by the
+// Operator CA when operatorCA.defaultServer is set, and self-signed otherwise. The expiry is reported in the status of the
+// NginxIngressController. It returns the time to wait before the certificate must be regenerated.
+func (r *NginxIngressControllerReconciler) reconcileDefaultSecret(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController) (time.Duration, error) {
+	if instance.Spec.DefaultSecret != nil {
+		instance.Status.DefaultCertificateExpiry = nil
+		defaultCertificateExpiryDays.delete(instance.Namespace, instance.Name)
+		return 0, nil
+	}
+	if certManagerIssuesDefaultServer(instance) {
+		// The expiry is reported from the status of the cert-manager Certificate.
+		defaultCertificateExpiryDays.delete(instance.Namespace, instance.Name)
+		return 0, nil
+	}
+
+	cs := certificateSecret{
+		name:          instance.Name,
+		params:        defaultCertificateParams(instance),
+		renewBefore:   defaultCertificateRenewBeforeFor(instance),
+		description:   "self-signed certificate of the default server",
+		renewedReason: eventReasonDefaultCertificateRenewed,
+	}
+	if instance.Spec.OperatorCA != nil && instance.Spec.OperatorCA.DefaultServer {
+		ca, err := r.operatorCAKeyPair(ctx)
+		if err != nil {
+			return 0, err
+		}
+		cs.ca = ca
+		cs.description = "certificate of the default server"
+	}
+
+	notAfter, created, err := r.reconcileCertificateSecret(ctx, log, instance, cs)
+	if err != nil {
+		return 0, err
+	}
+	if created && cs.ca == nil {
+		log.Info("Warning! A custom self-signed TLS Secret has been created for the default server. "+
+			"Update your 'DefaultSecret' with your own Secret in Production",
+			"Secret.Namespace", instance.Namespace, "Secret.Name", cs.name)
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, eventReasonSelfSignedCertificateGenerated,
+			"Created Secret %v with a self-signed certificate for the default server. Set defaultSecret to your own Secret in production", cs.name)
+	} else if created {
+		r.recordCreated(instance, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: cs.name}})
+	}
+
+	if notAfter.IsZero() {
+		instance.Status.DefaultCertificateExpiry = nil
+		defaultCertificateExpiryDays.delete(instance.Namespace, instance.Name)
+		return 0, nil
+	}
+
+	expiry := metav1.NewTime(notAfter)
+	instance.Status.DefaultCertificateExpiry = &expiry
+	setDefaultCertificateExpiryMetric(instance.Namespace, instance.Name, notAfter)
+
+	return cs.renewal(notAfter, time.Now()), nil
+}
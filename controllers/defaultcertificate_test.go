@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDefaultCertificateRenewal(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		notAfter    time.Time
+		renewBefore time.Duration
+		expected    time.Duration
+		msg         string
+	}{
+		{
+			notAfter:    now.Add(365 * 24 * time.Hour),
+			renewBefore: k8sv1beta1.DefaultCertificateRenewBefore,
+			expected:    335 * 24 * time.Hour,
+			msg:         "valid certificate",
+		},
+		{
+			notAfter:    now.Add(10 * 24 * time.Hour),
+			renewBefore: k8sv1beta1.DefaultCertificateRenewBefore,
+			expected:    0,
+			msg:         "certificate in the renewal window",
+		},
+		{
+			notAfter:    now.Add(-time.Hour),
+			renewBefore: k8sv1beta1.DefaultCertificateRenewBefore,
+			expected:    0,
+			msg:         "expired certificate",
+		},
+	}
+
+	for _, test := range tests {
+		if result := defaultCertificateRenewal(test.notAfter, test.renewBefore, now); result != test.expected {
+			t.Errorf("defaultCertificateRenewal() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestEarliestRequeue(t *testing.T) {
+	tests := []struct {
+		durations []time.Duration
+		expected  time.Duration
+	}{
+		{durations: []time.Duration{0, 0}, expected: 0},
+		{durations: []time.Duration{0, time.Hour}, expected: time.Hour},
+		{durations: []time.Duration{time.Minute, time.Hour}, expected: time.Minute},
+	}
+
+	for _, test := range tests {
+		if result := earliestRequeue(test.durations...); result != test.expected {
+			t.Errorf("earliestRequeue(%v) returned %v but expected %v", test.durations, result, test.expected)
+		}
+	}
+}
+
+func TestReconcileDefaultSecret(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add client-go scheme: (%v)", err)
+	}
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
+	}
+
+	newInstance := func(renewBefore time.Duration) *k8sv1beta1.NginxIngressController {
+		return &k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+			Spec: k8sv1beta1.NginxIngressControllerSpec{
+				DefaultCertificate: &k8sv1beta1.DefaultCertificate{
+					RenewBefore: &metav1.Duration{Duration: renewBefore},
+				},
+			},
+		}
+	}
+	ownedSecret := func(instance *k8sv1beta1.NginxIngressController) *corev1.Secret {
+		secret, err := defaultSecretForNginxIngressController(instance, s)
+		if err != nil {
+			t.Fatalf("defaultSecretForNginxIngressController() returned unexpected error %v", err)
+		}
+		return secret
+	}
+
+	tests := []struct {
+		instance        *k8sv1beta1.NginxIngressController
+		secret          func(instance *k8sv1beta1.NginxIngressController) *corev1.Secret
+		expectedRenewed bool
+		expectedEvents  int
+		msg             string
+	}{
+		{
+			instance:       newInstance(k8sv1beta1.DefaultCertificateRenewBefore),
+			expectedEvents: 1,
+			msg:            "secret created",
+		},
+		{
+			instance:       newInstance(k8sv1beta1.DefaultCertificateRenewBefore),
+			secret:         ownedSecret,
+			expectedEvents: 0,
+			msg:            "valid secret kept",
+		},
+		{
+			// The generated certificate is valid for 365 days, so it is always in a renewal window of 366 days.
+			instance:        newInstance(366 * 24 * time.Hour),
+			secret:          ownedSecret,
+			expectedRenewed: true,
+			expectedEvents:  1,
+			msg:             "secret in the renewal window renewed",
+		},
+		{
+			instance: newInstance(366 * 24 * time.Hour),
+			secret: func(instance *k8sv1beta1.NginxIngressController) *corev1.Secret {
+				secret := ownedSecret(instance)
+				secret.OwnerReferences = nil
+				return secret
+			},
+			expectedEvents: 0,
+			msg:            "secret not managed by the Operator kept",
+		},
+		{
+			instance: newInstance(k8sv1beta1.DefaultCertificateRenewBefore),
+			secret: func(instance *k8sv1beta1.NginxIngressController) *corev1.Secret {
+				previous := instance.DeepCopy()
+				previous.Spec.DefaultCertificate.CommonName = "previous.example.com"
+				return ownedSecret(previous)
+			},
+			expectedRenewed: true,
+			expectedEvents:  1,
+			msg:             "secret regenerated after defaultCertificate changed",
+		},
+		{
+			instance: newInstance(k8sv1beta1.DefaultCertificateRenewBefore),
+			secret: func(instance *k8sv1beta1.NginxIngressController) *corev1.Secret {
+				secret := ownedSecret(instance)
+				secret.Annotations = nil
+				return secret
+			},
+			expectedEvents: 0,
+			msg:            "secret without hash generated with the defaults kept",
+		},
+	}
+
+	for _, test := range tests {
+		builder := fake.NewClientBuilder().WithScheme(s)
+		var original *corev1.Secret
+		if test.secret != nil {
+			original = test.secret(test.instance)
+			builder = builder.WithObjects(original.DeepCopy())
+		}
+		c := builder.Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+		requeueAfter, err := r.reconcileDefaultSecret(context.Background(), logr.Discard(), test.instance)
+		if err != nil {
+			t.Fatalf("reconcileDefaultSecret() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+
+		secret := &corev1.Secret{}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(test.instance), secret); err != nil {
+			t.Fatalf("failed to get the default Secret for the case of %v: %v", test.msg, err)
+		}
+		if original != nil {
+			renewed := string(secret.Data[corev1.TLSCertKey]) != string(original.Data[corev1.TLSCertKey])
+			if renewed != test.expectedRenewed {
+				t.Errorf("reconcileDefaultSecret() renewed the certificate: %v but expected %v for the case of %v", renewed, test.expectedRenewed, test.msg)
+			}
+		}
+
+		notAfter, err := certificateNotAfter(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			t.Fatalf("certificateNotAfter() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		if test.instance.Status.DefaultCertificateExpiry == nil || !test.instance.Status.DefaultCertificateExpiry.Time.Equal(notAfter.Truncate(time.Second)) {
+			t.Errorf("reconcileDefaultSecret() set the expiry %v but expected %v for the case of %v", test.instance.Status.DefaultCertificateExpiry, notAfter, test.msg)
+		}
+
+		expectedRequeue := defaultCertificateRenewal(notAfter, defaultCertificateRenewBeforeFor(test.instance), time.Now())
+		if diff := expectedRequeue - requeueAfter; diff < -time.Minute || diff > time.Minute {
+			t.Errorf("reconcileDefaultSecret() returned requeueAfter %v but expected %v for the case of %v", requeueAfter, expectedRequeue, test.msg)
+		}
+
+		if events := recordedEvents(recorder); len(events) != test.expectedEvents {
+			t.Errorf("reconcileDefaultSecret() recorded events %v but expected %v events for the case of %v", events, test.expectedEvents, test.msg)
+		}
+	}
+}
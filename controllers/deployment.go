@@ -1,7 +1,7 @@
 package controllers
 
 import (
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -9,7 +9,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func deploymentForNginxIngressController(instance *k8sv1alpha1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.Deployment, error) {
+func deploymentForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.Deployment, error) {
 	runAsUser := new(int64)
 	allowPrivilegeEscalation := new(bool)
 	*runAsUser = 101
@@ -39,16 +39,7 @@ func deploymentForNginxIngressController(instance *k8sv1alpha1.NginxIngressContr
 							Image:           generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag),
 							ImagePullPolicy: corev1.PullPolicy(instance.Spec.Image.PullPolicy),
 							Args:            generatePodArgs(instance),
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          "http",
-									ContainerPort: 80,
-								},
-								{
-									Name:          "https",
-									ContainerPort: 443,
-								},
-							},
+							Ports:           containerPortsForNginxIngressController(instance),
 							SecurityContext: &corev1.SecurityContext{
 								Capabilities: &corev1.Capabilities{
 									Drop: []corev1.Capability{"ALL"},
@@ -81,40 +72,10 @@ func deploymentForNginxIngressController(instance *k8sv1alpha1.NginxIngressContr
 			},
 		},
 	}
+	applyProbes(&dep.Spec.Template.Spec.Containers[0], instance)
+	applyPodCustomization(&dep.Spec.Template, instance)
 	if err := ctrl.SetControllerReference(instance, dep, scheme); err != nil {
 		return nil, err
 	}
 	return dep, nil
 }
-
-func hasDeploymentChanged(dep *appsv1.Deployment, instance *k8sv1alpha1.NginxIngressController) bool {
-	defaultReplicaCount := int32(1)
-	if dep.Spec.Replicas != nil && instance.Spec.Replicas == nil && *dep.Spec.Replicas != defaultReplicaCount ||
-		dep.Spec.Replicas != nil && instance.Spec.Replicas != nil && *dep.Spec.Replicas != *instance.Spec.Replicas {
-		return true
-	}
-
-	// There is only 1 container in our template
-	container := dep.Spec.Template.Spec.Containers[0]
-	if container.Image != generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag) {
-		return true
-	}
-
-	if container.ImagePullPolicy != corev1.PullPolicy(instance.Spec.Image.PullPolicy) {
-		return true
-	}
-
-	return hasDifferentArguments(container, instance)
-}
-
-func updateDeployment(dep *appsv1.Deployment, instance *k8sv1alpha1.NginxIngressController) *appsv1.Deployment {
-	dep.Spec.Replicas = instance.Spec.Replicas
-	if instance.Spec.Replicas == nil {
-		defaultReplicaCount := new(int32)
-		*defaultReplicaCount = 1
-		dep.Spec.Replicas = defaultReplicaCount
-	}
-	dep.Spec.Template.Spec.Containers[0].Image = generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag)
-	dep.Spec.Template.Spec.Containers[0].Args = generatePodArgs(instance)
-	return dep
-}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Reasons of the events recorded on a NginxIngressController, in addition to the reasons of the conditions.
+const (
+	eventReasonCreated                        = "Created"
+	eventReasonUpdated                        = "Updated"
+	eventReasonDeleted                        = "Deleted"
+	eventReasonSelfSignedCertificateGenerated = "SelfSignedCertificateGenerated"
+	eventReasonDefaultCertificateRenewed      = "DefaultCertificateRenewed"
+	eventReasonCertificateRenewed             = "CertificateRenewed"
+)
+
+// kindOf returns the kind of the object, or an empty string if the object is not registered in the scheme.
+func (r *NginxIngressControllerReconciler) kindOf(obj client.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return ""
+	}
+	return gvk.Kind
+}
+
+// recordCreated records a Normal event on the NginxIngressController for an object created by the Operator.
+func (r *NginxIngressControllerReconciler) recordCreated(instance *k8sv1beta1.NginxIngressController, obj client.Object) {
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonCreated, "Created %v %v", r.kindOf(obj), obj.GetName())
+}
+
+// recordUpdated records a Normal event on the NginxIngressController for an object updated by the Operator.
+func (r *NginxIngressControllerReconciler) recordUpdated(instance *k8sv1beta1.NginxIngressController, obj client.Object) {
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonUpdated, "Updated %v %v", r.kindOf(obj), obj.GetName())
+}
+
+// deleteIfExists deletes the object and records a Normal event on the NginxIngressController if the object existed.
+func (r *NginxIngressControllerReconciler) deleteIfExists(ctx context.Context, instance *k8sv1beta1.NginxIngressController, obj client.Object) error {
+	err := r.Delete(ctx, obj)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonDeleted, "Deleted %v %v", r.kindOf(obj), obj.GetName())
+	return nil
+}
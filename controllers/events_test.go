@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordedEvents returns the events recorded by the fake recorder so far.
+func recordedEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestDeleteIfExists(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add client-go scheme: (%v)", err)
+	}
+
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &NginxIngressControllerReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(cm).Build(),
+		Scheme:   s,
+		Recorder: recorder,
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := r.deleteIfExists(context.Background(), instance, cm.DeepCopy()); err != nil {
+			t.Fatalf("deleteIfExists() returned unexpected error %v", err)
+		}
+	}
+
+	expected := []string{"Normal Deleted Deleted ConfigMap my-nginx-ingress"}
+	if diff := cmp.Diff(expected, recordedEvents(recorder)); diff != "" {
+		t.Errorf("deleteIfExists() recorded events mismatch (-want +got):\n%s", diff)
+	}
+}
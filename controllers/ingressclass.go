@@ -1,23 +1,212 @@
 package controllers
 
 import (
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-func ingressClassForNginxIngressController(instance *k8sv1alpha1.NginxIngressController) *networking.IngressClass {
-	ingressClassName := "nginx"
+// ingressClassController is the controller of the IngressClasses of the NGINX Ingress Controller.
+const ingressClassController = "nginx.org/ingress-controller"
+
+// reasonIngressClassConflict is the reason of the PrerequisitesMet condition when the IngressClass belongs to another controller.
+const reasonIngressClassConflict = "IngressClassConflict"
+
+// errIngressClassConflict is returned when the IngressClass of the NginxIngressController belongs to another controller.
+var errIngressClassConflict = errors.New("IngressClass conflict")
+
+// ingressClassName returns the name of the IngressClass of the NginxIngressController.
+func ingressClassName(instance *k8sv1beta1.NginxIngressController) string {
 	if instance.Spec.IngressClass != "" {
-		ingressClassName = instance.Spec.IngressClass
+		return instance.Spec.IngressClass
 	}
+	return k8sv1beta1.DefaultIngressClass
+}
+
+// ingressClassForNginxIngressController returns the IngressClass of the NginxIngressController, marked as the default class of
+// the cluster when isDefault is set and referencing the IngressClassParameters with the same name when hasParameters is set.
+// The IngressClass is labeled as managed by the Operator, as it is cluster-scoped and can be shared by several
+// NginxIngressControllers.
+func ingressClassForNginxIngressController(instance *k8sv1beta1.NginxIngressController, isDefault bool, hasParameters bool) *networking.IngressClass {
 	ic := &networking.IngressClass{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: ingressClassName,
+			Name:   ingressClassName(instance),
+			Labels: map[string]string{managedByLabel: managedByValue},
 		},
 		Spec: networking.IngressClassSpec{
-			Controller: "nginx.org/ingress-controller",
+			Controller: ingressClassController,
 		},
 	}
+	if isDefault {
+		ic.Annotations = map[string]string{networking.AnnotationIsDefaultIngressClass: "true"}
+	}
+	if hasParameters {
+		ic.Spec.Parameters = ingressClassParametersReference(ic.Name)
+	}
 	return ic
 }
+
+// isIngressClassManaged returns whether the IngressClass is managed by the Operator: it has the managed-by label, or it was
+// created by an earlier version of the Operator, before the IngressClasses were labeled. Earlier versions created the
+// IngressClass named after the ingressClass of a NginxIngressController, so an unlabeled IngressClass is only adopted when one
+// of the NginxIngressControllers uses it and the Operator is its only field manager.
+func isIngressClassManaged(ic *networking.IngressClass, instances []k8sv1beta1.NginxIngressController) bool {
+	if ic.Labels[managedByLabel] == managedByValue {
+		return true
+	}
+	if len(ic.ManagedFields) == 0 || ic.Spec.Controller != ingressClassController || len(usesIngressClass(instances, ic.Name)) == 0 {
+		return false
+	}
+	for _, entry := range ic.ManagedFields {
+		if !legacyFieldManagers[entry.Manager] && entry.Manager != fieldManager {
+			return false
+		}
+	}
+	return true
+}
+
+// usesIngressClass returns the NginxIngressControllers that are not being deleted and use the IngressClass.
+func usesIngressClass(instances []k8sv1beta1.NginxIngressController, name string) []*k8sv1beta1.NginxIngressController {
+	var result []*k8sv1beta1.NginxIngressController
+	for i := range instances {
+		if instances[i].DeletionTimestamp == nil && ingressClassName(&instances[i]) == name {
+			result = append(result, &instances[i])
+		}
+	}
+	return result
+}
+
+// isDefaultIngressClass returns whether any of the NginxIngressControllers marks their IngressClass as the default class.
+func isDefaultIngressClass(instances []*k8sv1beta1.NginxIngressController) bool {
+	for _, instance := range instances {
+		if instance.Spec.DefaultIngressClass {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressClassFailureReason returns the reason of the PrerequisitesMet condition for the error of reconcileIngressClass.
+func ingressClassFailureReason(err error) string {
+	if errors.Is(err, errIngressClassConflict) {
+		return reasonIngressClassConflict
+	}
+	return reasonPrerequisitesFailed
+}
+
+// hasIngressClassAPI returns whether the IngressClass API is available. It is available from k8s 1.18+
+func hasIngressClassAPI() bool {
+	minVersion, _ := version.ParseGeneric("v1.18.0")
+	return RunningK8sVersion.AtLeast(minVersion)
+}
+
+// reconcileIngressClass applies the IngressClass of the NginxIngressController and deletes the IngressClasses managed by the
+// Operator that no NginxIngressController uses, for example after ingressClass is changed. The IngressClass is the default class
+// of the cluster when any of the NginxIngressControllers that use it sets defaultIngressClass, and references an
+// IngressClassParameters resource when they set ingressClassParameters. An IngressClass created out of band for the NGINX
+// Ingress Controller is used as is, while an IngressClass of another controller is a conflict.
+func (r *NginxIngressControllerReconciler) reconcileIngressClass(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController) error {
+	instances := &k8sv1beta1.NginxIngressControllerList{}
+	if err := r.List(ctx, instances); err != nil {
+		return err
+	}
+
+	name := ingressClassName(instance)
+	existing := &networking.IngressClass{}
+	err := r.Get(ctx, types.NamespacedName{Name: name}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil && !isIngressClassManaged(existing, instances.Items) {
+		if existing.Spec.Controller != ingressClassController {
+			return fmt.Errorf("%w: IngressClass %v belongs to the controller %v", errIngressClassConflict, name, existing.Spec.Controller)
+		}
+		log.V(1).Info("IngressClass not managed by the Operator, using it as is", "IngressClass.Name", name)
+	} else {
+		users := usesIngressClass(instances.Items, name)
+		parameters, err := ingressClassParametersSpec(users)
+		if err != nil {
+			return err
+		}
+		// The IngressClassParameters are applied before the IngressClass that references them.
+		if parameters != nil {
+			if err := r.apply(ctx, instance, ingressClassParametersForNginxIngressController(instance, parameters)); err != nil {
+				return err
+			}
+			log.V(1).Info("IngressClassParameters applied", "IngressClassParameters.Name", name)
+		}
+		ic := ingressClassForNginxIngressController(instance, isDefaultIngressClass(users), parameters != nil)
+		if err := r.apply(ctx, instance, ic); err != nil {
+			return err
+		}
+		log.V(1).Info("IngressClass applied", "IngressClass.Name", name)
+	}
+
+	return r.deleteUnusedIngressClasses(ctx, instance)
+}
+
+// deleteUnusedIngressClasses deletes the IngressClasses managed by the Operator that are not used by any of the
+// NginxIngressControllers, and the IngressClassParameters of the IngressClasses whose NginxIngressControllers don't set
+// ingressClassParameters. The NginxIngressControllers being deleted no longer use their IngressClass.
+func (r *NginxIngressControllerReconciler) deleteUnusedIngressClasses(ctx context.Context, instance *k8sv1beta1.NginxIngressController) error {
+	instances := &k8sv1beta1.NginxIngressControllerList{}
+	if err := r.List(ctx, instances); err != nil {
+		return err
+	}
+	classes := &networking.IngressClassList{}
+	if err := r.List(ctx, classes, client.MatchingLabels{managedByLabel: managedByValue}); err != nil {
+		return err
+	}
+	for i := range classes.Items {
+		if len(usesIngressClass(instances.Items, classes.Items[i].Name)) > 0 {
+			continue
+		}
+		if err := r.deleteIfExists(ctx, instance, &classes.Items[i]); err != nil {
+			return err
+		}
+	}
+
+	parameters := &k8sv1beta1.IngressClassParametersList{}
+	if err := r.List(ctx, parameters, client.MatchingLabels{managedByLabel: managedByValue}); err != nil {
+		return err
+	}
+	for i := range parameters.Items {
+		spec, err := ingressClassParametersSpec(usesIngressClass(instances.Items, parameters.Items[i].Name))
+		// The IngressClassParameters are kept while the NginxIngressControllers of the IngressClass are in conflict.
+		if err != nil || spec != nil {
+			continue
+		}
+		if err := r.deleteIfExists(ctx, instance, &parameters.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requestsForIngressClass returns a function that maps an IngressClass or IngressClassParameters to the NginxIngressControllers
+// that use the IngressClass.
+func requestsForIngressClass(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		instances := &k8sv1beta1.NginxIngressControllerList{}
+		if err := c.List(context.TODO(), instances); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, instance := range usesIngressClass(instances.Items, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(instance)})
+		}
+		return requests
+	}
+}
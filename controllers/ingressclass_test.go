@@ -1,50 +1,271 @@
 package controllers
 
 import (
+	"context"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestIngressClassForNginxIngressController(t *testing.T) {
-	instance := &k8sv1alpha1.NginxIngressController{
-		Spec: k8sv1alpha1.NginxIngressControllerSpec{
-			IngressClass: "nginx",
+	instance := &k8sv1beta1.NginxIngressController{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			IngressClass: "my-nginx",
 		},
 	}
 	expected := &networking.IngressClass{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "nginx",
+			Name:   "my-nginx",
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "nginx-ingress-operator"},
 		},
 		Spec: networking.IngressClassSpec{
 			Controller: "nginx.org/ingress-controller",
 		},
 	}
 
-	result := ingressClassForNginxIngressController(instance)
+	result := ingressClassForNginxIngressController(instance, false, false)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("ingressClassForNginxIngressController() mismatch (-want +got):\n%s", diff)
 	}
 }
 
 func TestIngressClassForNginxIngressControllerDefault(t *testing.T) {
-	instance := &k8sv1alpha1.NginxIngressController{
-		Spec: k8sv1alpha1.NginxIngressControllerSpec{},
+	instance := &k8sv1beta1.NginxIngressController{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{},
 	}
 	expected := &networking.IngressClass{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "nginx",
+			Name:        "nginx",
+			Labels:      map[string]string{"app.kubernetes.io/managed-by": "nginx-ingress-operator"},
+			Annotations: map[string]string{"ingressclass.kubernetes.io/is-default-class": "true"},
 		},
 		Spec: networking.IngressClassSpec{
 			Controller: "nginx.org/ingress-controller",
 		},
 	}
 
-	result := ingressClassForNginxIngressController(instance)
+	result := ingressClassForNginxIngressController(instance, true, false)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("ingressClassForNginxIngressController() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestIsIngressClassManaged(t *testing.T) {
+	instances := []k8sv1beta1.NginxIngressController{{Spec: k8sv1beta1.NginxIngressControllerSpec{IngressClass: "nginx"}}}
+
+	tests := []struct {
+		ic       *networking.IngressClass
+		expected bool
+		msg      string
+	}{
+		{
+			ic: &networking.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{managedByLabel: managedByValue}},
+				Spec:       networking.IngressClassSpec{Controller: ingressClassController},
+			},
+			expected: true,
+			msg:      "labeled IngressClass",
+		},
+		{
+			ic: &networking.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "manager"}}},
+				Spec:       networking.IngressClassSpec{Controller: ingressClassController},
+			},
+			expected: true,
+			msg:      "IngressClass created by an earlier version of the Operator",
+		},
+		{
+			ic: &networking.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "manager"}}},
+				Spec:       networking.IngressClassSpec{Controller: ingressClassController},
+			},
+			expected: false,
+			msg:      "unlabeled IngressClass not used by any NginxIngressController",
+		},
+		{
+			ic: &networking.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl-client-side-apply"}}},
+				Spec:       networking.IngressClassSpec{Controller: ingressClassController},
+			},
+			expected: false,
+			msg:      "IngressClass created by the cluster administrator",
+		},
+		{
+			ic: &networking.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec:       networking.IngressClassSpec{Controller: ingressClassController},
+			},
+			expected: false,
+			msg:      "IngressClass without managed fields",
+		},
+		{
+			ic: &networking.IngressClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "manager"}}},
+				Spec:       networking.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+			},
+			expected: false,
+			msg:      "IngressClass of another controller",
+		},
+	}
+
+	for _, test := range tests {
+		if result := isIngressClassManaged(test.ic, instances); result != test.expected {
+			t.Errorf("isIngressClassManaged() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestIsDefaultIngressClass(t *testing.T) {
+	deleted := metav1.Now()
+	instances := []k8sv1beta1.NginxIngressController{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "a"},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "nginx"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "b"},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "nginx", DefaultIngressClass: true},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "c", DeletionTimestamp: &deleted},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "internal", DefaultIngressClass: true},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "d", Namespace: "d"},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "internal"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expected bool
+		msg      string
+	}{
+		{name: "nginx", expected: true, msg: "one of the NginxIngressControllers sets defaultIngressClass"},
+		{name: "internal", expected: false, msg: "the NginxIngressController that sets defaultIngressClass is being deleted"},
+		{name: "unused", expected: false, msg: "IngressClass not used"},
+	}
+
+	for _, test := range tests {
+		if result := isDefaultIngressClass(usesIngressClass(instances, test.name)); result != test.expected {
+			t.Errorf("isDefaultIngressClass() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestReconcileIngressClassNotManaged(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "nginx"},
+	}
+
+	tests := []struct {
+		controller    string
+		expectedError bool
+		msg           string
+	}{
+		{controller: ingressClassController, expectedError: false, msg: "IngressClass created out of band for the NGINX Ingress Controller"},
+		{controller: "k8s.io/ingress-nginx", expectedError: true, msg: "IngressClass of another controller"},
+	}
+
+	for _, test := range tests {
+		existing := &networking.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+			Spec:       networking.IngressClassSpec{Controller: test.controller},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(instance.DeepCopy(), existing).Build()
+		r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+		err := r.reconcileIngressClass(context.Background(), logr.Discard(), instance)
+		if (err != nil) != test.expectedError {
+			t.Errorf("reconcileIngressClass() returned error %v for the case of %v", err, test.msg)
+		}
+		if test.expectedError && ingressClassFailureReason(err) != reasonIngressClassConflict {
+			t.Errorf("ingressClassFailureReason() returned %v but expected %v for the case of %v", ingressClassFailureReason(err), reasonIngressClassConflict, test.msg)
+		}
+
+		ic := &networking.IngressClass{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: "nginx"}, ic); err != nil {
+			t.Fatalf("failed to get IngressClass: %v", err)
+		}
+		if diff := cmp.Diff(existing.Spec, ic.Spec); diff != "" || len(ic.Labels) > 0 {
+			t.Errorf("reconcileIngressClass() changed the IngressClass for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestDeleteUnusedIngressClasses(t *testing.T) {
+	s := newCATestScheme(t)
+	deleted := metav1.Now()
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", DeletionTimestamp: &deleted,
+			Finalizers: []string{finalizer}},
+		Spec: k8sv1beta1.NginxIngressControllerSpec{IngressClass: "deleted"},
+	}
+	other := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "renamed"},
+	}
+	unmanaged := &networking.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "unmanaged"},
+		Spec:       networking.IngressClassSpec{Controller: ingressClassController},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(instance, other, unmanaged,
+		ingressClassForNginxIngressController(instance, false, false),
+		ingressClassForNginxIngressController(other, false, false),
+		ingressClassForNginxIngressController(&k8sv1beta1.NginxIngressController{Spec: k8sv1beta1.NginxIngressControllerSpec{IngressClass: "previous"}}, false, false)).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+	if err := r.deleteUnusedIngressClasses(context.Background(), instance); err != nil {
+		t.Fatalf("deleteUnusedIngressClasses() returned unexpected error %v", err)
+	}
+
+	classes := &networking.IngressClassList{}
+	if err := c.List(context.Background(), classes); err != nil {
+		t.Fatalf("failed to list IngressClasses: %v", err)
+	}
+	var names []string
+	for _, ic := range classes.Items {
+		names = append(names, ic.Name)
+	}
+	if diff := cmp.Diff([]string{"renamed", "unmanaged"}, names); diff != "" {
+		t.Errorf("deleteUnusedIngressClasses() IngressClasses mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedEvents := []string{
+		"Normal Deleted Deleted IngressClass deleted",
+		"Normal Deleted Deleted IngressClass previous",
+	}
+	if diff := cmp.Diff(expectedEvents, recordedEvents(recorder)); diff != "" {
+		t.Errorf("deleteUnusedIngressClasses() recorded events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequestsForIngressClass(t *testing.T) {
+	s := newCATestScheme(t)
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&k8sv1beta1.NginxIngressController{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "a"}},
+		&k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "b"},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "internal"},
+		},
+	).Build()
+
+	result := requestsForIngressClass(c)(&networking.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}})
+	expected := []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: "a", Name: "a"}}}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("requestsForIngressClass() mismatch (-want +got):\n%s", diff)
+	}
+}
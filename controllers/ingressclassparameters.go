@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"fmt"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ingressClassParametersKind is the kind of the resource referenced by the parameters of the IngressClasses.
+const ingressClassParametersKind = "IngressClassParameters"
+
+// resolvedObjectReference returns the reference with the namespace of the NginxIngressController when it has no namespace.
+func resolvedObjectReference(instance *k8sv1beta1.NginxIngressController, ref *k8sv1beta1.ObjectReference) *k8sv1beta1.ObjectReference {
+	if ref == nil {
+		return nil
+	}
+	result := *ref
+	if result.Namespace == "" {
+		result.Namespace = instance.Namespace
+	}
+	return &result
+}
+
+// globalConfigurationForNginxIngressController returns the GlobalConfiguration used by the Ingress Controller: the one set in
+// the spec, or else the one set in the ingressClassParameters.
+func globalConfigurationForNginxIngressController(instance *k8sv1beta1.NginxIngressController) *k8sv1beta1.ObjectReference {
+	if instance.Spec.GlobalConfiguration != nil {
+		return instance.Spec.GlobalConfiguration
+	}
+	if instance.Spec.IngressClassParameters != nil {
+		return instance.Spec.IngressClassParameters.GlobalConfiguration
+	}
+	return nil
+}
+
+// resolvedIngressClassParameters returns the ingressClassParameters of the NginxIngressController with the namespaces of the
+// references resolved, as the IngressClassParameters resource is cluster-scoped.
+func resolvedIngressClassParameters(instance *k8sv1beta1.NginxIngressController) *k8sv1beta1.IngressClassParametersSpec {
+	if instance.Spec.IngressClassParameters == nil {
+		return nil
+	}
+	return &k8sv1beta1.IngressClassParametersSpec{
+		GlobalConfiguration: resolvedObjectReference(instance, instance.Spec.IngressClassParameters.GlobalConfiguration),
+	}
+}
+
+// ingressClassParametersSpec returns the ingressClassParameters set by the NginxIngressControllers that use an IngressClass, or
+// nil when none of them sets ingressClassParameters. The NginxIngressControllers that set different settings for the same
+// IngressClass are a conflict.
+func ingressClassParametersSpec(instances []*k8sv1beta1.NginxIngressController) (*k8sv1beta1.IngressClassParametersSpec, error) {
+	var result *k8sv1beta1.IngressClassParametersSpec
+	var from *k8sv1beta1.NginxIngressController
+	for _, instance := range instances {
+		spec := resolvedIngressClassParameters(instance)
+		if spec == nil {
+			continue
+		}
+		if result == nil {
+			result, from = spec, instance
+			continue
+		}
+		if !equality.Semantic.DeepEqual(result, spec) {
+			return nil, fmt.Errorf("%w: NginxIngressControllers %v/%v and %v/%v set different ingressClassParameters for the IngressClass %v",
+				errIngressClassConflict, from.Namespace, from.Name, instance.Namespace, instance.Name, ingressClassName(instance))
+		}
+	}
+	return result, nil
+}
+
+// ingressClassParametersForNginxIngressController returns the IngressClassParameters referenced by the IngressClass of the
+// NginxIngressController. It has the name of the IngressClass and is labeled as managed by the Operator.
+func ingressClassParametersForNginxIngressController(instance *k8sv1beta1.NginxIngressController, spec *k8sv1beta1.IngressClassParametersSpec) *k8sv1beta1.IngressClassParameters {
+	return &k8sv1beta1.IngressClassParameters{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   ingressClassName(instance),
+			Labels: map[string]string{managedByLabel: managedByValue},
+		},
+		Spec: *spec,
+	}
+}
+
+// ingressClassParametersReference returns the reference to the IngressClassParameters with the name of the IngressClass. The
+// scope is not set, so the API servers older than 1.21 accept the reference, and defaults to Cluster.
+func ingressClassParametersReference(name string) *networking.IngressClassParametersReference {
+	group := k8sv1beta1.GroupVersion.Group
+	return &networking.IngressClassParametersReference{
+		APIGroup: &group,
+		Kind:     ingressClassParametersKind,
+		Name:     name,
+	}
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIngressClassParametersSpec(t *testing.T) {
+	newInstance := func(namespace string, parameters *k8sv1beta1.IngressClassParametersSpec) *k8sv1beta1.NginxIngressController {
+		return &k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: namespace},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "nginx", IngressClassParameters: parameters},
+		}
+	}
+	local := &k8sv1beta1.IngressClassParametersSpec{
+		GlobalConfiguration: &k8sv1beta1.ObjectReference{Name: "nginx-configuration"},
+	}
+	resolved := &k8sv1beta1.IngressClassParametersSpec{
+		GlobalConfiguration: &k8sv1beta1.ObjectReference{Namespace: "a", Name: "nginx-configuration"},
+	}
+
+	tests := []struct {
+		instances     []*k8sv1beta1.NginxIngressController
+		expected      *k8sv1beta1.IngressClassParametersSpec
+		expectedError bool
+		msg           string
+	}{
+		{
+			instances: []*k8sv1beta1.NginxIngressController{newInstance("a", nil), newInstance("b", nil)},
+			expected:  nil,
+			msg:       "no ingressClassParameters",
+		},
+		{
+			instances: []*k8sv1beta1.NginxIngressController{newInstance("a", local), newInstance("b", nil)},
+			expected:  resolved,
+			msg:       "references resolved to the namespace of the NginxIngressController",
+		},
+		{
+			instances: []*k8sv1beta1.NginxIngressController{newInstance("a", local), newInstance("b", resolved)},
+			expected:  resolved,
+			msg:       "same settings",
+		},
+		{
+			instances:     []*k8sv1beta1.NginxIngressController{newInstance("a", local), newInstance("b", local)},
+			expectedError: true,
+			msg:           "references resolved to different namespaces",
+		},
+	}
+
+	for _, test := range tests {
+		result, err := ingressClassParametersSpec(test.instances)
+		if (err != nil) != test.expectedError {
+			t.Errorf("ingressClassParametersSpec() returned error %v for the case of %v", err, test.msg)
+		}
+		if test.expectedError && ingressClassFailureReason(err) != reasonIngressClassConflict {
+			t.Errorf("ingressClassFailureReason() returned %v but expected %v for the case of %v", ingressClassFailureReason(err), reasonIngressClassConflict, test.msg)
+		}
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("ingressClassParametersSpec() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestIngressClassForNginxIngressControllerParameters(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{IngressClass: "my-nginx"},
+	}
+	group := "k8s.nginx.org"
+	expected := &networking.IngressClassParametersReference{APIGroup: &group, Kind: "IngressClassParameters", Name: "my-nginx"}
+
+	result := ingressClassForNginxIngressController(instance, false, true)
+	if diff := cmp.Diff(expected, result.Spec.Parameters); diff != "" {
+		t.Errorf("ingressClassForNginxIngressController() parameters mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDeleteUnusedIngressClassParameters(t *testing.T) {
+	s := newCATestScheme(t)
+	parameters := &k8sv1beta1.IngressClassParametersSpec{GlobalConfiguration: &k8sv1beta1.ObjectReference{Name: "nginx-configuration"}}
+	withParameters := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "a"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "with-parameters", IngressClassParameters: parameters},
+	}
+	withoutParameters := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "b"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{IngressClass: "without-parameters"},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(withParameters, withoutParameters,
+		ingressClassParametersForNginxIngressController(withParameters, resolvedIngressClassParameters(withParameters)),
+		ingressClassParametersForNginxIngressController(withoutParameters, parameters),
+		&k8sv1beta1.IngressClassParameters{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged"}}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+	if err := r.deleteUnusedIngressClasses(context.Background(), withParameters); err != nil {
+		t.Fatalf("deleteUnusedIngressClasses() returned unexpected error %v", err)
+	}
+
+	list := &k8sv1beta1.IngressClassParametersList{}
+	if err := c.List(context.Background(), list); err != nil {
+		t.Fatalf("failed to list IngressClassParameters: %v", err)
+	}
+	var names []string
+	for _, p := range list.Items {
+		names = append(names, p.Name)
+	}
+	if diff := cmp.Diff([]string{"unmanaged", "with-parameters"}, names); diff != "" {
+		t.Errorf("deleteUnusedIngressClasses() IngressClassParameters mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Normal Deleted Deleted IngressClassParameters without-parameters"}, recordedEvents(recorder)); diff != "" {
+		t.Errorf("deleteUnusedIngressClasses() recorded events mismatch (-want +got):\n%s", diff)
+	}
+}
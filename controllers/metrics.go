@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "nginx_ingress_operator"
+
+// Phases of the reconciliation of a NginxIngressController reported in the reconcile outcome metric.
+const (
+	phasePrerequisites = "prerequisites"
+	phaseWorkload      = "workload"
+	phaseService       = "service"
+	phaseConfigMap     = "configmap"
+)
+
+var (
+	managedControllers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "managed_controllers",
+			Help:      "Number of NginxIngressController resources managed by the Operator",
+		},
+		[]string{"type", "plus"},
+	)
+	controllerReadyReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "controller_ready_replicas",
+			Help:      "Number of available Ingress Controller pods of a NginxIngressController",
+		},
+		[]string{"namespace", "name"},
+	)
+	controllerDesiredReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "controller_desired_replicas",
+			Help:      "Number of Ingress Controller pods a NginxIngressController is expected to run",
+		},
+		[]string{"namespace", "name"},
+	)
+	reconcileOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "reconcile_outcomes_total",
+			Help:      "Number of outcomes of the phases of the reconciliation of NginxIngressController resources",
+		},
+		[]string{"phase", "result"},
+	)
+	crdInstallFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "crd_install_failures_total",
+			Help:      "Number of failures to install the Ingress Controller CustomResourceDefinitions",
+		},
+	)
+	defaultCertificateExpiryDays = newCertificateExpiryCollector(prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "default_certificate_expiry_days"),
+		"Number of days until the self-signed certificate of the default server of a NginxIngressController expires",
+		[]string{"namespace", "name"}, nil,
+	))
+)
+
+// certificateExpiryCollector reports the days until the certificates expire. The days are computed when the metrics are
+// collected, as the NginxIngressControllers are not reconciled again until their certificates are renewed.
+type certificateExpiryCollector struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+
+	mu       sync.Mutex
+	notAfter map[types.NamespacedName]time.Time
+}
+
+func newCertificateExpiryCollector(desc *prometheus.Desc) *certificateExpiryCollector {
+	return &certificateExpiryCollector{desc: desc, now: time.Now, notAfter: map[types.NamespacedName]time.Time{}}
+}
+
+// Describe implements prometheus.Collector.
+func (c *certificateExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *certificateExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, notAfter := range c.notAfter {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, notAfter.Sub(now).Hours()/24, key.Namespace, key.Name)
+	}
+}
+
+func (c *certificateExpiryCollector) set(namespace string, name string, notAfter time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notAfter[types.NamespacedName{Namespace: namespace, Name: name}] = notAfter
+}
+
+func (c *certificateExpiryCollector) delete(namespace string, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.notAfter, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		managedControllers,
+		controllerReadyReplicas,
+		controllerDesiredReplicas,
+		reconcileOutcomes,
+		crdInstallFailures,
+		defaultCertificateExpiryDays,
+	)
+}
+
+// observePhase counts the outcome of a phase of the reconciliation.
+func observePhase(phase string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileOutcomes.WithLabelValues(phase, result).Inc()
+}
+
+// setReplicasMetrics sets the replica metrics of the NginxIngressController from the status of its workload.
+func setReplicasMetrics(instance *k8sv1beta1.NginxIngressController, ws workloadStatus) {
+	controllerReadyReplicas.WithLabelValues(instance.Namespace, instance.Name).Set(float64(ws.available))
+	controllerDesiredReplicas.WithLabelValues(instance.Namespace, instance.Name).Set(float64(ws.desired))
+}
+
+// setDefaultCertificateExpiryMetric sets the expiry of the self-signed default certificate of the NginxIngressController.
+func setDefaultCertificateExpiryMetric(namespace string, name string, notAfter time.Time) {
+	defaultCertificateExpiryDays.set(namespace, name, notAfter)
+}
+
+// deleteInstanceMetrics removes the metrics of a NginxIngressController that no longer exists.
+func deleteInstanceMetrics(namespace string, name string) {
+	controllerReadyReplicas.DeleteLabelValues(namespace, name)
+	controllerDesiredReplicas.DeleteLabelValues(namespace, name)
+	defaultCertificateExpiryDays.delete(namespace, name)
+}
+
+// setManagedControllersMetric counts the NginxIngressController resources by type and NGINX Plus usage.
+func setManagedControllersMetric(instances []k8sv1beta1.NginxIngressController) {
+	managedControllers.Reset()
+	for _, instance := range instances {
+		if instance.DeletionTimestamp != nil {
+			continue
+		}
+		managedControllers.WithLabelValues(string(instance.Spec.Type), strconv.FormatBool(instance.Spec.NginxPlus)).Inc()
+	}
+}
+
+// updateManagedControllersMetric lists the NginxIngressController resources in the cluster to update the managed controllers metric.
+func (r *NginxIngressControllerReconciler) updateManagedControllersMetric(ctx context.Context) error {
+	list := &k8sv1beta1.NginxIngressControllerList{}
+	if err := r.List(ctx, list); err != nil {
+		return err
+	}
+	setManagedControllersMetric(list.Items)
+	return nil
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetManagedControllersMetric(t *testing.T) {
+	now := metav1.Now()
+	instances := []k8sv1beta1.NginxIngressController{
+		{Spec: k8sv1beta1.NginxIngressControllerSpec{Type: k8sv1beta1.WorkloadKindDeployment}},
+		{Spec: k8sv1beta1.NginxIngressControllerSpec{Type: k8sv1beta1.WorkloadKindDeployment}},
+		{Spec: k8sv1beta1.NginxIngressControllerSpec{Type: k8sv1beta1.WorkloadKindDaemonSet, NginxPlus: true}},
+		{
+			ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+			Spec:       k8sv1beta1.NginxIngressControllerSpec{Type: k8sv1beta1.WorkloadKindDaemonSet},
+		},
+	}
+
+	setManagedControllersMetric(instances)
+
+	expected := `
+# HELP nginx_ingress_operator_managed_controllers Number of NginxIngressController resources managed by the Operator
+# TYPE nginx_ingress_operator_managed_controllers gauge
+nginx_ingress_operator_managed_controllers{plus="false",type="deployment"} 2
+nginx_ingress_operator_managed_controllers{plus="true",type="daemonset"} 1
+`
+	if err := testutil.CollectAndCompare(managedControllers, strings.NewReader(expected)); err != nil {
+		t.Errorf("setManagedControllersMetric() mismatch: %v", err)
+	}
+}
+
+func TestObservePhase(t *testing.T) {
+	reconcileOutcomes.Reset()
+
+	observePhase(phaseWorkload, nil)
+	observePhase(phaseWorkload, nil)
+	observePhase(phaseService, errors.New("failed"))
+
+	tests := []struct {
+		phase    string
+		result   string
+		expected float64
+	}{
+		{phase: phaseWorkload, result: "success", expected: 2},
+		{phase: phaseService, result: "error", expected: 1},
+		{phase: phaseConfigMap, result: "success", expected: 0},
+	}
+
+	for _, test := range tests {
+		if result := testutil.ToFloat64(reconcileOutcomes.WithLabelValues(test.phase, test.result)); result != test.expected {
+			t.Errorf("reconcile outcomes of %v %v returned %v but expected %v", test.phase, test.result, result, test.expected)
+		}
+	}
+}
+
+func TestDeleteInstanceMetrics(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+	}
+	setReplicasMetrics(instance, workloadStatus{desired: 3, available: 2})
+
+	if result := testutil.ToFloat64(controllerDesiredReplicas.WithLabelValues(instance.Namespace, instance.Name)); result != 3 {
+		t.Errorf("setReplicasMetrics() set desired replicas %v but expected 3", result)
+	}
+
+	deleteInstanceMetrics(instance.Namespace, instance.Name)
+
+	if count := testutil.CollectAndCount(controllerReadyReplicas); count != 0 {
+		t.Errorf("deleteInstanceMetrics() left %v ready replicas metrics", count)
+	}
+}
+
+func TestDefaultCertificateExpiryMetric(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCertificateExpiryCollector(defaultCertificateExpiryDays.desc)
+	c.now = func() time.Time { return now }
+
+	c.set("my-nginx-ingress", "my-nginx-ingress", now.Add(48*time.Hour))
+
+	expected := `
+# HELP nginx_ingress_operator_default_certificate_expiry_days Number of days until the self-signed certificate of the default server of a NginxIngressController expires
+# TYPE nginx_ingress_operator_default_certificate_expiry_days gauge
+nginx_ingress_operator_default_certificate_expiry_days{name="my-nginx-ingress",namespace="my-nginx-ingress"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Errorf("certificateExpiryCollector mismatch: %v", err)
+	}
+
+	// The days are computed on every collection without a new reconciliation.
+	now = now.Add(24 * time.Hour)
+	expected = strings.Replace(expected, "} 2", "} 1", 1)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Errorf("certificateExpiryCollector mismatch after a day: %v", err)
+	}
+
+	c.delete("my-nginx-ingress", "my-nginx-ingress")
+	if count := testutil.CollectAndCount(c); count != 0 {
+		t.Errorf("certificateExpiryCollector reported %v metrics after the delete", count)
+	}
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultWorkloadMigrationTimeout is the time the new workload has to become available when the type changes.
+const defaultWorkloadMigrationTimeout = 10 * time.Minute
+
+// Reasons of the WorkloadMigrating condition.
+const (
+	reasonMigrationInProgress = "MigrationInProgress"
+	reasonMigrationComplete   = "MigrationComplete"
+	reasonMigrationRolledBack = "MigrationRolledBack"
+)
+
+func isWorkloadMigrationEnabled(instance *k8sv1beta1.NginxIngressController) bool {
+	wm := instance.Spec.WorkloadMigration
+	return wm == nil || wm.Enable == nil || *wm.Enable
+}
+
+func workloadMigrationTimeout(instance *k8sv1beta1.NginxIngressController) time.Duration {
+	wm := instance.Spec.WorkloadMigration
+	if wm == nil || wm.Timeout == nil {
+		return defaultWorkloadMigrationTimeout
+	}
+	return wm.Timeout.Duration
+}
+
+// isWorkloadAvailable returns whether the workload has rolled out the latest spec and all of its desired pods are available.
+func isWorkloadAvailable(ws workloadStatus) bool {
+	return ws.failure == "" && ws.observed && ws.updated >= ws.desired && ws.available >= ws.desired
+}
+
+// isWorkloadMigrationRolledBack returns whether the migration to the type of the current spec was rolled back.
+// A rolled back migration is retried when the spec of the NginxIngressController changes.
+func isWorkloadMigrationRolledBack(instance *k8sv1beta1.NginxIngressController) bool {
+	cond := meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionWorkloadMigrating)
+	return cond != nil && cond.Reason == reasonMigrationRolledBack && cond.ObservedGeneration == instance.Generation
+}
+
+func workloadStatusFor(obj client.Object) workloadStatus {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return workloadStatusForDeployment(w)
+	case *appsv1.DaemonSet:
+		return workloadStatusForDaemonSet(w)
+	}
+	return workloadStatus{}
+}
+
+func workloadKindName(obj client.Object) string {
+	if _, ok := obj.(*appsv1.DaemonSet); ok {
+		return "DaemonSet"
+	}
+	return "Deployment"
+}
+
+// migrateWorkload applies the workload of the current type while the workload of the previous type exists. The previous
+// workload keeps serving traffic until the new one is available, and it is removed then. If the new workload doesn't become
+// available before the timeout, it is removed and the previous workload is kept.
+// It returns the status of the workload that serves traffic and the time to wait before checking the migration again.
+func (r *NginxIngressControllerReconciler) migrateWorkload(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController, desired client.Object, previous client.Object) (workloadStatus, time.Duration, error) {
+	desiredKind, previousKind := workloadKindName(desired), workloadKindName(previous)
+
+	if isWorkloadMigrationRolledBack(instance) {
+		if err := r.deleteIfExists(ctx, instance, desired); err != nil {
+			return workloadStatus{}, 0, err
+		}
+		ws := workloadStatusFor(previous)
+		ws.failure = meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionWorkloadMigrating).Message
+		return ws, 0, nil
+	}
+
+	if err := r.apply(ctx, instance, desired); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to apply %v", desiredKind), "Namespace", desired.GetNamespace(), "Name", desired.GetName())
+		return workloadStatus{}, 0, err
+	}
+	ws := workloadStatusFor(desired)
+
+	if isWorkloadAvailable(ws) {
+		if err := r.deleteIfExists(ctx, instance, previous); err != nil {
+			return ws, 0, err
+		}
+		msg := fmt.Sprintf("Migrated from %v to %v", previousKind, desiredKind)
+		log.Info(fmt.Sprintf("%v is available, removed %v", desiredKind, previousKind))
+		r.Recorder.Event(instance, corev1.EventTypeNormal, reasonMigrationComplete, msg)
+		setCondition(instance, k8sv1beta1.ConditionWorkloadMigrating, metav1.ConditionFalse, reasonMigrationComplete, msg)
+		return ws, 0, nil
+	}
+
+	timeout := workloadMigrationTimeout(instance)
+	elapsed := time.Since(desired.GetCreationTimestamp().Time)
+	if elapsed >= timeout {
+		if err := r.deleteIfExists(ctx, instance, desired); err != nil {
+			return ws, 0, err
+		}
+		msg := fmt.Sprintf("%v did not become available within %v, kept %v", desiredKind, timeout, previousKind)
+		log.Info(fmt.Sprintf("Rolled back the migration: %v", msg))
+		r.Recorder.Event(instance, corev1.EventTypeWarning, reasonMigrationRolledBack, msg)
+		setCondition(instance, k8sv1beta1.ConditionWorkloadMigrating, metav1.ConditionFalse, reasonMigrationRolledBack, msg)
+
+		previousStatus := workloadStatusFor(previous)
+		previousStatus.failure = msg
+		return previousStatus, 0, nil
+	}
+
+	setCondition(instance, k8sv1beta1.ConditionWorkloadMigrating, metav1.ConditionTrue, reasonMigrationInProgress,
+		fmt.Sprintf("Waiting for %v to become available before removing %v: %v of %v pods available", desiredKind, previousKind, ws.available, ws.desired))
+	return ws, timeout - elapsed, nil
+}
+
+// getPreviousWorkload returns whether the workload of the previous type exists, reading it into the given object.
+func (r *NginxIngressControllerReconciler) getPreviousWorkload(ctx context.Context, previous client.Object) (bool, error) {
+	err := r.Get(ctx, client.ObjectKeyFromObject(previous), previous)
+	if err != nil && errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWorkloadMigrationSettings(t *testing.T) {
+	disable := false
+	timeout := metav1.Duration{Duration: 5 * time.Minute}
+
+	tests := []struct {
+		migration       *k8sv1beta1.WorkloadMigration
+		expectedEnabled bool
+		expectedTimeout time.Duration
+		msg             string
+	}{
+		{
+			migration:       nil,
+			expectedEnabled: true,
+			expectedTimeout: defaultWorkloadMigrationTimeout,
+			msg:             "defaults",
+		},
+		{
+			migration:       &k8sv1beta1.WorkloadMigration{Enable: &disable, Timeout: &timeout},
+			expectedEnabled: false,
+			expectedTimeout: 5 * time.Minute,
+			msg:             "disabled with timeout",
+		},
+	}
+
+	for _, test := range tests {
+		instance := &k8sv1beta1.NginxIngressController{
+			Spec: k8sv1beta1.NginxIngressControllerSpec{WorkloadMigration: test.migration},
+		}
+		if result := isWorkloadMigrationEnabled(instance); result != test.expectedEnabled {
+			t.Errorf("isWorkloadMigrationEnabled() returned %v but expected %v for the case of %v", result, test.expectedEnabled, test.msg)
+		}
+		if result := workloadMigrationTimeout(instance); result != test.expectedTimeout {
+			t.Errorf("workloadMigrationTimeout() returned %v but expected %v for the case of %v", result, test.expectedTimeout, test.msg)
+		}
+	}
+}
+
+func TestIsWorkloadAvailable(t *testing.T) {
+	tests := []struct {
+		ws       workloadStatus
+		expected bool
+		msg      string
+	}{
+		{
+			ws:       workloadStatus{desired: 3, updated: 3, available: 3, observed: true},
+			expected: true,
+			msg:      "all pods available",
+		},
+		{
+			ws:       workloadStatus{desired: 3, updated: 3, available: 2, observed: true},
+			expected: false,
+			msg:      "pods unavailable",
+		},
+		{
+			ws:       workloadStatus{desired: 0, observed: false},
+			expected: false,
+			msg:      "not observed",
+		},
+		{
+			ws:       workloadStatus{desired: 3, updated: 3, available: 3, observed: true, failure: "quota exceeded"},
+			expected: false,
+			msg:      "rollout failed",
+		},
+	}
+
+	for _, test := range tests {
+		if result := isWorkloadAvailable(test.ws); result != test.expected {
+			t.Errorf("isWorkloadAvailable(%+v) returned %v but expected %v for the case of %v", test.ws, result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestMigrateWorkloadRolledBack(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add client-go scheme: (%v)", err)
+	}
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
+	}
+
+	msg := "DaemonSet did not become available within 10m0s, kept Deployment"
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", Generation: 2},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{Type: k8sv1beta1.WorkloadKindDaemonSet},
+	}
+	setCondition(instance, k8sv1beta1.ConditionWorkloadMigrating, metav1.ConditionFalse, reasonMigrationRolledBack, msg)
+
+	replicas := int32(2)
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace},
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(dep, ds).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: recorder}
+
+	ws, requeueAfter, err := r.migrateWorkload(context.Background(), logr.Discard(), instance, ds.DeepCopy(), dep.DeepCopy())
+	if err != nil {
+		t.Fatalf("migrateWorkload() returned unexpected error %v", err)
+	}
+	if requeueAfter != 0 {
+		t.Errorf("migrateWorkload() returned requeueAfter %v but expected 0", requeueAfter)
+	}
+	if ws.failure != msg || ws.available != 2 {
+		t.Errorf("migrateWorkload() returned %+v but expected the status of the Deployment with the failure %q", ws, msg)
+	}
+
+	err = c.Get(context.Background(), client.ObjectKeyFromObject(ds), &appsv1.DaemonSet{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("migrateWorkload() did not remove the DaemonSet of the rolled back migration: %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(dep), &appsv1.Deployment{}); err != nil {
+		t.Errorf("migrateWorkload() removed the Deployment of the rolled back migration: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"Normal Deleted Deleted DaemonSet my-nginx-ingress"}, recordedEvents(recorder)); diff != "" {
+		t.Errorf("migrateWorkload() recorded events mismatch (-want +got):\n%s", diff)
+	}
+
+	instance.Generation = 3
+	if isWorkloadMigrationRolledBack(instance) {
+		t.Errorf("isWorkloadMigrationRolledBack() returned true after the spec changed")
+	}
+}
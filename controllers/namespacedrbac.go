@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hasNamespacedRBAC returns whether the Ingress Controller is granted access to the resources of the namespaces it uses only,
+// instead of the resources of all the namespaces.
+func hasNamespacedRBAC(instance *k8sv1beta1.NginxIngressController) bool {
+	return instance.Spec.WatchNamespace != ""
+}
+
+// namespacesForNginxIngressController returns the namespaces the Ingress Controller reads resources from: its own namespace,
+// the watched namespace, and the namespaces of the GlobalConfiguration and of the TLS Secrets passed to the Ingress Controller.
+func namespacesForNginxIngressController(instance *k8sv1beta1.NginxIngressController) []string {
+	namespaces := map[string]bool{instance.Namespace: true, instance.Spec.WatchNamespace: true}
+	refs := []*k8sv1beta1.ObjectReference{globalConfigurationForNginxIngressController(instance), instance.Spec.DefaultSecret, instance.Spec.WildcardTLS}
+	if instance.Spec.Prometheus != nil && instance.Spec.Prometheus.Enable {
+		refs = append(refs, instance.Spec.Prometheus.Secret)
+	}
+	for _, ref := range refs {
+		if ref != nil && ref.Namespace != "" {
+			namespaces[ref.Namespace] = true
+		}
+	}
+
+	var result []string
+	for ns := range namespaces {
+		result = append(result, ns)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isClusterScopedRule returns whether the rule grants access to cluster-scoped resources.
+func isClusterScopedRule(rule rbacv1.PolicyRule) bool {
+	for _, group := range rule.APIGroups {
+		if group == "networking.k8s.io" && containsString(rule.Resources, "ingressclasses") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitClusterScopedRules splits the rules into the rules for namespaced resources, granted with Roles, and the rules for
+// cluster-scoped resources, granted with the ClusterRole.
+func splitClusterScopedRules(rules []rbacv1.PolicyRule) (namespaced []rbacv1.PolicyRule, clusterScoped []rbacv1.PolicyRule) {
+	for _, rule := range rules {
+		if isClusterScopedRule(rule) {
+			clusterScoped = append(clusterScoped, rule)
+		} else {
+			namespaced = append(namespaced, rule)
+		}
+	}
+	return namespaced, clusterScoped
+}
+
+// setRBACOwner sets the NginxIngressController as the controller of the RBAC resource when they are in the same namespace, so the
+// resource is garbage collected, and labels the resource with the NginxIngressController otherwise.
+func setRBACOwner(instance *k8sv1beta1.NginxIngressController, obj client.Object, scheme *runtime.Scheme) error {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range ownerLabels(instance) {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+
+	if obj.GetNamespace() != instance.Namespace {
+		return nil
+	}
+	return ctrl.SetControllerReference(instance, obj, scheme)
+}
+
+func roleForNginxIngressController(instance *k8sv1beta1.NginxIngressController, namespace string, rules []rbacv1.PolicyRule, scheme *runtime.Scheme) (*rbacv1.Role, error) {
+	role := &rbacv1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      rbacName(instance),
+			Namespace: namespace,
+		},
+		Rules: rules,
+	}
+	return role, setRBACOwner(instance, role, scheme)
+}
+
+func roleBindingForNginxIngressController(instance *k8sv1beta1.NginxIngressController, namespace string, scheme *runtime.Scheme) (*rbacv1.RoleBinding, error) {
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      rbacName(instance),
+			Namespace: namespace,
+		},
+		Subjects: []rbacv1.Subject{subjectForServiceAccount(instance.Namespace, instance.Name)},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     rbacName(instance),
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+	return rb, setRBACOwner(instance, rb, scheme)
+}
+
+// applyRoles grants the ServiceAccount of the NginxIngressController the rules in the namespaces with a Role and a RoleBinding in
+// each namespace.
+func (r *NginxIngressControllerReconciler) applyRoles(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController, namespaces []string, rules []rbacv1.PolicyRule) error {
+	for _, ns := range namespaces {
+		role, err := roleForNginxIngressController(instance, ns, rules, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, instance, role); err != nil {
+			return err
+		}
+		rb, err := roleBindingForNginxIngressController(instance, ns, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, instance, rb); err != nil {
+			return err
+		}
+		log.V(1).Info("Role and RoleBinding applied", "Namespace", ns, "Name", role.Name)
+	}
+	return nil
+}
+
+// deleteRoles deletes the Roles and RoleBindings of the NginxIngressController in the namespaces it no longer uses.
+func (r *NginxIngressControllerReconciler) deleteRoles(ctx context.Context, instance *k8sv1beta1.NginxIngressController, namespaces []string) error {
+	selector := client.MatchingLabels(ownerLabels(instance))
+
+	roles := &rbacv1.RoleList{}
+	if err := r.List(ctx, roles, selector); err != nil {
+		return err
+	}
+	for i := range roles.Items {
+		if !containsString(namespaces, roles.Items[i].Namespace) {
+			if err := r.deleteIfExists(ctx, instance, &roles.Items[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.List(ctx, bindings, selector); err != nil {
+		return err
+	}
+	for i := range bindings.Items {
+		if !containsString(namespaces, bindings.Items[i].Namespace) {
+			if err := r.deleteIfExists(ctx, instance, &bindings.Items[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNamespacesForNginxIngressController(t *testing.T) {
+	tests := []struct {
+		spec     k8sv1beta1.NginxIngressControllerSpec
+		expected []string
+		msg      string
+	}{
+		{
+			spec:     k8sv1beta1.NginxIngressControllerSpec{WatchNamespace: "my-nginx-ingress"},
+			expected: []string{"my-nginx-ingress"},
+			msg:      "watching its own namespace",
+		},
+		{
+			spec:     k8sv1beta1.NginxIngressControllerSpec{WatchNamespace: "tenant"},
+			expected: []string{"my-nginx-ingress", "tenant"},
+			msg:      "watching another namespace",
+		},
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{
+				WatchNamespace:      "tenant",
+				GlobalConfiguration: &k8sv1beta1.ObjectReference{Namespace: "global", Name: "gc"},
+			},
+			expected: []string{"global", "my-nginx-ingress", "tenant"},
+			msg:      "GlobalConfiguration in another namespace",
+		},
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{
+				WatchNamespace: "tenant",
+				IngressClassParameters: &k8sv1beta1.IngressClassParametersSpec{
+					GlobalConfiguration: &k8sv1beta1.ObjectReference{Namespace: "global", Name: "gc"},
+				},
+			},
+			expected: []string{"global", "my-nginx-ingress", "tenant"},
+			msg:      "GlobalConfiguration of the ingressClassParameters in another namespace",
+		},
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{
+				WatchNamespace: "tenant",
+				DefaultSecret:  &k8sv1beta1.ObjectReference{Namespace: "default-secrets", Name: "default"},
+				WildcardTLS:    &k8sv1beta1.ObjectReference{Namespace: "wildcard-secrets", Name: "wildcard"},
+				Prometheus: &k8sv1beta1.Prometheus{
+					Enable: true,
+					Secret: &k8sv1beta1.ObjectReference{Namespace: "prometheus-secrets", Name: "prometheus"},
+				},
+			},
+			expected: []string{"default-secrets", "my-nginx-ingress", "prometheus-secrets", "tenant", "wildcard-secrets"},
+			msg:      "TLS Secrets in other namespaces",
+		},
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{
+				WatchNamespace: "tenant",
+				DefaultSecret:  &k8sv1beta1.ObjectReference{Name: "default"},
+				Prometheus: &k8sv1beta1.Prometheus{
+					Secret: &k8sv1beta1.ObjectReference{Namespace: "prometheus-secrets", Name: "prometheus"},
+				},
+			},
+			expected: []string{"my-nginx-ingress", "tenant"},
+			msg:      "Secret in its own namespace and Prometheus disabled",
+		},
+	}
+
+	for _, test := range tests {
+		instance := &k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+			Spec:       test.spec,
+		}
+		if diff := cmp.Diff(test.expected, namespacesForNginxIngressController(instance)); diff != "" {
+			t.Errorf("namespacesForNginxIngressController() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestSplitClusterScopedRules(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{Spec: k8sv1beta1.NginxIngressControllerSpec{WatchNamespace: "tenant"}}
+	rules := rulesForNginxIngressController(instance)
+
+	namespaced, clusterScoped := splitClusterScopedRules(rules)
+	if len(namespaced) != len(rules)-1 {
+		t.Errorf("splitClusterScopedRules() returned %v namespaced rules but expected all the rules except the IngressClass rule", len(namespaced))
+	}
+	for _, rule := range namespaced {
+		if containsString(rule.Resources, "ingressclasses") {
+			t.Errorf("splitClusterScopedRules() returned the namespaced rule %v for cluster-scoped resources", rule)
+		}
+	}
+	expected := []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingressclasses"}}}
+	if diff := cmp.Diff(expected, clusterScoped); diff != "" {
+		t.Errorf("splitClusterScopedRules() cluster-scoped rules mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRoleForNginxIngressController(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{WatchNamespace: "tenant"},
+	}
+
+	own, err := roleForNginxIngressController(instance, instance.Namespace, nil, s)
+	if err != nil {
+		t.Fatalf("roleForNginxIngressController() returned unexpected error %v", err)
+	}
+	if !metav1.IsControlledBy(own, instance) {
+		t.Errorf("roleForNginxIngressController() returned a Role in the namespace of the NginxIngressController without the controller reference")
+	}
+
+	other, err := roleForNginxIngressController(instance, "tenant", nil, s)
+	if err != nil {
+		t.Fatalf("roleForNginxIngressController() returned unexpected error %v", err)
+	}
+	if len(other.OwnerReferences) != 0 {
+		t.Errorf("roleForNginxIngressController() returned a Role in another namespace with owner references %v", other.OwnerReferences)
+	}
+	for _, role := range []*rbacv1.Role{own, other} {
+		if diff := cmp.Diff(ownerLabels(instance), role.Labels); diff != "" {
+			t.Errorf("roleForNginxIngressController() returned labels mismatch in namespace %v (-want +got):\n%s", role.Namespace, diff)
+		}
+		if role.Name != "nginx-ingress-my-nginx-ingress-my-nginx-ingress" {
+			t.Errorf("roleForNginxIngressController() returned the name %v", role.Name)
+		}
+	}
+}
+
+func TestDeleteRoles(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress", UID: "uid"},
+		Spec:       k8sv1beta1.NginxIngressControllerSpec{WatchNamespace: "tenant"},
+	}
+	other := &k8sv1beta1.NginxIngressController{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}}
+
+	current, _ := roleForNginxIngressController(instance, "tenant", nil, s)
+	stale, _ := roleForNginxIngressController(instance, "previous-tenant", nil, s)
+	staleBinding, _ := roleBindingForNginxIngressController(instance, "previous-tenant", s)
+	otherRole, _ := roleForNginxIngressController(other, "previous-tenant", nil, s)
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(current, stale, staleBinding, otherRole).Build()
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+	if err := r.deleteRoles(context.Background(), instance, []string{"my-nginx-ingress", "tenant"}); err != nil {
+		t.Fatalf("deleteRoles() returned unexpected error %v", err)
+	}
+	for _, obj := range []client.Object{current, otherRole} {
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Errorf("deleteRoles() removed %v/%v: %v", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	for _, obj := range []client.Object{stale, staleBinding} {
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); !errors.IsNotFound(err) {
+			t.Errorf("deleteRoles() did not remove %v/%v: %v", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+
+	if err := r.deleteRoles(context.Background(), instance, nil); err != nil {
+		t.Fatalf("deleteRoles() returned unexpected error %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(current), current); !errors.IsNotFound(err) {
+		t.Errorf("deleteRoles() did not remove %v/%v: %v", current.Namespace, current.Name, err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(otherRole), otherRole); err != nil {
+		t.Errorf("deleteRoles() removed the Role of another NginxIngressController: %v", err)
+	}
+}
@@ -19,24 +19,32 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/nginxinc/nginx-ingress-operator/controllers/scc"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
+	"k8s.io/client-go/tools/record"
+
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 )
 
 const (
@@ -50,6 +58,17 @@ type NginxIngressControllerReconciler struct {
 	Scheme       *runtime.Scheme
 	SccAPIExists bool
 	Mgr          ctrl.Manager
+	Recorder     record.EventRecorder
+	// CA is the certificate authority managed by the Operator. It is nil when the namespace of the Operator is unknown.
+	CA *CertificateAuthority
+	// SharedResources reconciles the KIC CRDs shared by all the Ingress Controllers. It is nil in namespaced mode.
+	SharedResources *SharedResourcesReconciler
+	// Namespaced is set when the Operator runs without write access to the cluster-scoped resources. The CRDs, the ClusterRoles,
+	// the ClusterRoleBindings, the IngressClasses and the SCC are installed by the cluster administrator and only verified.
+	Namespaced bool
+	// Reader reads the cluster-scoped resources verified in namespaced mode without caching them, so the Operator doesn't need
+	// to list and watch them.
+	Reader client.Reader
 }
 
 //+kubebuilder:rbac:groups=k8s.nginx.org,resources=nginxingresscontrollers,verbs=get;list;watch;create;update;patch;delete
@@ -60,8 +79,10 @@ type NginxIngressControllerReconciler struct {
 //+kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets,verbs=get;list;watch;create;update;patch;delete
 
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=list;watch;get
-//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;create;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=k8s.nginx.org,resources=ingressclassparameters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses/status,verbs=update
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;create;update;patch;delete
 //+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;create;delete;update
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 
@@ -81,13 +102,18 @@ type NginxIngressControllerReconciler struct {
 func (r *NginxIngressControllerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrllog.FromContext(ctx)
 
-	instance := &k8sv1alpha1.NginxIngressController{}
+	if err := r.updateManagedControllersMetric(ctx); err != nil {
+		log.Error(err, "Failed to update the managed controllers metric")
+	}
+
+	instance := &k8sv1beta1.NginxIngressController{}
 	err := r.Get(ctx, req.NamespacedName, instance)
 	if err != nil && errors.IsNotFound(err) {
 		// Request object not found, could have been deleted after reconcile request.
 		// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 		// Return and don't requeue
 		log.Info("NginxIngressController resource not found. Ignoring since object must be deleted")
+		deleteInstanceMetrics(req.Namespace, req.Name)
 		return ctrl.Result{}, nil
 	} else if err != nil {
 		// Error reading the object - requeue the request.
@@ -127,6 +153,8 @@ func (r *NginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 		}
 	}
 
+	status := instance.Status.DeepCopy()
+
 	// Namespace could have been deleted in the middle of the reconcile
 	ns := &v1.Namespace{}
 	err = r.Get(ctx, types.NamespacedName{Name: instance.Namespace, Namespace: v1.NamespaceAll}, ns)
@@ -138,114 +166,108 @@ func (r *NginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, err
 	}
 
-	if err := r.createCommonResources(log); err != nil {
+	clusterResourcesReady, err := r.reconcileClusterResources(ctx, instance)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if !clusterResourcesReady {
+		// The Ingress Controller fails to start without the CRDs it watches and the cluster-scoped resources it uses.
+		if !equality.Semantic.DeepEqual(*status, instance.Status) {
+			if err := r.Status().Update(ctx, instance); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: sharedResourcesPollInterval}, nil
+	}
 
 	err = r.checkPrerequisites(log, instance)
 	if err != nil {
-		return ctrl.Result{}, err
+		observePhase(phasePrerequisites, err)
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionPrerequisitesMet, reasonPrerequisitesFailed, err)
 	}
-	if strings.ToLower(instance.Spec.Type) == "deployment" {
-		found := &appsv1.Deployment{}
-		dep, err := deploymentForNginxIngressController(instance, r.Scheme)
-		if err != nil {
-			return ctrl.Result{}, err
+
+	// The IngressClass is installed by the cluster administrator in namespaced mode.
+	if hasIngressClassAPI() && !r.Namespaced {
+		if err := r.reconcileIngressClass(ctx, log, instance); err != nil {
+			observePhase(phasePrerequisites, err)
+			return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionPrerequisitesMet, ingressClassFailureReason(err), err)
 		}
-		err = r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found)
-		if err != nil && errors.IsNotFound(err) {
-			log.Info("Creating a new Deployment for NGINX Ingress Controller", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+	}
 
-			err = r.Create(ctx, dep)
-			if err != nil {
-				log.Error(err, "Failed to create new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
-				return ctrl.Result{}, err
-			}
-		} else if err != nil {
-			log.Error(err, "Failed to get Deployment")
-			return ctrl.Result{}, err
-		} else if hasDeploymentChanged(found, instance) {
-			log.Info("NginxIngressController spec has changed, updating Deployment")
-			updated := updateDeployment(found, instance)
-			err = r.Update(ctx, updated)
-			if err != nil {
+	// The cert-manager Certificates are reconciled first, so the Secrets cert-manager no longer issues are removed before the
+	// Operator generates them again.
+	certsReady, err := r.reconcileCertManagerCertificates(ctx, log, instance)
+	if err != nil {
+		observePhase(phasePrerequisites, err)
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionPrerequisitesMet, reasonCertificatesFailed, err)
+	}
+	if !certsReady {
+		// The Ingress Controller doesn't start without its TLS Secrets, so it is deployed once cert-manager has issued them.
+		setCondition(instance, k8sv1beta1.ConditionPrerequisitesMet, metav1.ConditionFalse, reasonCertificatesPending,
+			"Waiting for the cert-manager Certificates to be ready")
+		if !equality.Semantic.DeepEqual(*status, instance.Status) {
+			if err := r.Status().Update(ctx, instance); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
+		return ctrl.Result{RequeueAfter: certManagerPollInterval}, nil
+	}
 
-		// Remove possible DaemonSet
-		ds, err := daemonSetForNginxIngressController(instance, r.Scheme)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-		if err := r.Delete(ctx, ds); client.IgnoreNotFound(err) != nil {
-			return ctrl.Result{}, err
-		}
-	} else if strings.ToLower(instance.Spec.Type) == "daemonset" {
-		found := &appsv1.DaemonSet{}
-		ds, err := daemonSetForNginxIngressController(instance, r.Scheme)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-		err = r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found)
-		if err != nil && errors.IsNotFound(err) {
-			log.Info("Creating a new DaemonSet for NGINX Ingress Controller", "DaemonSet.Namespace", ds.Namespace, "DaemonSet.Name", ds.Name)
+	certRequeueAfter, err := r.reconcileDefaultSecret(ctx, log, instance)
+	if err != nil {
+		observePhase(phasePrerequisites, err)
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionPrerequisitesMet, reasonPrerequisitesFailed, err)
+	}
 
-			err = r.Create(ctx, ds)
-			if err != nil {
-				log.Error(err, "Failed to create new DaemonSet", "DaemonSet.Namespace", ds.Namespace, "DaemonSet.Name", ds.Name)
-				return ctrl.Result{}, err
-			}
-		} else if err != nil {
-			return ctrl.Result{}, err
-		} else if hasDaemonSetChanged(found, instance) {
-			log.Info("NginxIngressController spec has changed, updating DaemonSet")
-			updated := updateDaemonSet(found, instance)
-			err = r.Update(ctx, updated)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-		}
+	issuedRequeueAfter, err := r.reconcileIssuedSecrets(ctx, log, instance)
+	if err != nil {
+		observePhase(phasePrerequisites, err)
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionPrerequisitesMet, reasonPrerequisitesFailed, err)
+	}
 
-		// Remove possible Deployment
-		dep, err := deploymentForNginxIngressController(instance, r.Scheme)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-		if err := r.Delete(ctx, dep); client.IgnoreNotFound(err) != nil {
-			return ctrl.Result{}, err
+	if r.SccAPIExists && !r.Namespaced {
+		if err := r.reconcileSCC(log, instance); err != nil {
+			observePhase(phasePrerequisites, err)
+			return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionPrerequisitesMet, reasonSCCUpdateFailed, err)
 		}
+	}
+	observePhase(phasePrerequisites, nil)
+	setCondition(instance, k8sv1beta1.ConditionPrerequisitesMet, metav1.ConditionTrue, reasonPrerequisitesCreated, "")
 
+	ws, requeueAfter, err := r.reconcileWorkload(ctx, log, instance)
+	observePhase(phaseWorkload, err)
+	if err != nil {
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionDegraded, reasonWorkloadFailed, err)
 	}
 
 	svc, err := serviceForNginxIngressController(instance, r.Scheme)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	var extraLabels map[string]string
-	var extraAnnotations map[string]string
-	if instance.Spec.Service != nil {
-		extraLabels = instance.Spec.Service.ExtraLabels
-		extraAnnotations = instance.Spec.Service.ExtraAnnotations
-	}
-	res, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, serviceMutateFn(svc, instance.Spec.ServiceType, extraLabels, extraAnnotations))
-	log.V(1).Info(fmt.Sprintf("Service %s %s", svc.Name, res))
+	err = r.apply(ctx, instance, svc)
+	observePhase(phaseService, err)
 	if err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionDegraded, reasonServiceFailed, err)
 	}
+	log.V(1).Info("Service applied", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
 
 	cm, err := configMapForNginxIngressController(instance, r.Scheme)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	res, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, configMapMutateFn(cm, instance.Spec.ConfigMapData))
-	log.V(1).Info(fmt.Sprintf("ConfigMap %s %s", svc.Name, res))
+	err = r.apply(ctx, instance, cm)
+	observePhase(phaseConfigMap, err)
 	if err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.reportFailure(ctx, instance, k8sv1beta1.ConditionDegraded, reasonConfigMapFailed, err)
 	}
+	log.V(1).Info("ConfigMap applied", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+
+	instance.Status.Deployed = true
+	instance.Status.ObservedGeneration = instance.Generation
+	setWorkloadStatus(instance, ws)
+	setReplicasMetrics(instance, ws)
 
-	if !instance.Status.Deployed {
-		instance.Status.Deployed = true
+	if !equality.Semantic.DeepEqual(*status, instance.Status) {
 		err := r.Status().Update(ctx, instance)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -254,45 +276,98 @@ func (r *NginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 
 	log.Info("Finish reconcile for NginxIngressController")
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: earliestRequeue(requeueAfter, certRequeueAfter, issuedRequeueAfter)}, nil
 }
 
-// createIfNotExists creates a new object. If the object exists, does nothing. It returns whether the object existed before or not.
-func (r *NginxIngressControllerReconciler) createIfNotExists(object client.Object) (bool, error) {
-	err := r.Create(context.TODO(), object)
-	if err != nil && errors.IsAlreadyExists(err) {
-		return true, nil
+// reconcileWorkload applies the Deployment or DaemonSet of the Ingress Controller, removes the workload of the other type
+// and returns the status of the workload and the time to wait before reconciling again, if any.
+// When the type changes and the workload migration is enabled, the workload of the other type is removed only once
+// the new workload is available.
+func (r *NginxIngressControllerReconciler) reconcileWorkload(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController) (workloadStatus, time.Duration, error) {
+	dep, err := deploymentForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return workloadStatus{}, 0, err
+	}
+	ds, err := daemonSetForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return workloadStatus{}, 0, err
 	}
 
-	return false, err
-}
+	var desired, previous client.Object = dep, ds
+	if instance.Spec.Type == k8sv1beta1.WorkloadKindDaemonSet {
+		desired, previous = ds, dep
+	}
 
-func (r *NginxIngressControllerReconciler) finalizeNginxIngressController(log logr.Logger, instance *k8sv1alpha1.NginxIngressController) error {
-	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+	if isWorkloadMigrationEnabled(instance) {
+		exists, err := r.getPreviousWorkload(ctx, previous)
+		if err != nil {
+			return workloadStatus{}, 0, err
+		}
+		if exists {
+			return r.migrateWorkload(ctx, log, instance, desired, previous)
+		}
 
-	err := r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName, Namespace: v1.NamespaceAll}, crb)
-	if err != nil {
-		return err
+		cond := meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionWorkloadMigrating)
+		if cond != nil && cond.Reason != reasonMigrationComplete {
+			// The previous workload was removed out of band, so there is nothing left to migrate.
+			meta.RemoveStatusCondition(&instance.Status.Conditions, k8sv1beta1.ConditionWorkloadMigrating)
+		}
+	}
+
+	if err := r.apply(ctx, instance, desired); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to apply %v", workloadKindName(desired)), "Namespace", desired.GetNamespace(), "Name", desired.GetName())
+		return workloadStatus{}, 0, err
+	}
+	log.V(1).Info(fmt.Sprintf("%v applied", workloadKindName(desired)), "Namespace", desired.GetNamespace(), "Name", desired.GetName())
+
+	// Remove possible workload of the other type
+	if err := r.deleteIfExists(ctx, instance, previous); err != nil {
+		return workloadStatusFor(desired), 0, err
 	}
 
-	var subjects []rbacv1.Subject
-	for _, s := range crb.Subjects {
-		if s.Name != instance.Name || s.Namespace != instance.Namespace {
-			subjects = append(subjects, s)
+	return workloadStatusFor(desired), 0, nil
+}
+
+// earliestRequeue returns the shortest of the given durations that are not zero, or zero if all of them are.
+func earliestRequeue(durations ...time.Duration) time.Duration {
+	var earliest time.Duration
+	for _, d := range durations {
+		if d > 0 && (earliest == 0 || d < earliest) {
+			earliest = d
 		}
 	}
+	return earliest
+}
+
+func (r *NginxIngressControllerReconciler) finalizeNginxIngressController(log logr.Logger, instance *k8sv1beta1.NginxIngressController) error {
+	if err := r.deleteRoles(context.TODO(), instance, nil); err != nil {
+		return err
+	}
 
-	crb.Subjects = subjects
+	// The cluster-scoped resources are removed by the cluster administrator in namespaced mode.
+	if r.Namespaced {
+		log.Info("Successfully finalized NginxIngressController")
+		return nil
+	}
 
-	err = r.Update(context.TODO(), crb)
-	if err != nil {
+	if err := r.deleteClusterRoleAndBinding(context.TODO(), instance); err != nil {
+		return err
+	}
+	if err := r.removeFromLegacyClusterRoleBinding(context.TODO(), instance); err != nil {
 		return err
 	}
+	if hasIngressClassAPI() {
+		if err := r.deleteUnusedIngressClasses(context.TODO(), instance); err != nil {
+			return err
+		}
+	}
 
 	if r.SccAPIExists {
 		err := scc.RemoveServiceAccount(r.Client, instance.Namespace, instance.Name)
 		if err != nil {
-			return fmt.Errorf("failed to remove service account user from SCC: %w", err)
+			err = fmt.Errorf("failed to remove service account user from SCC: %w", err)
+			r.Recorder.Event(instance, v1.EventTypeWarning, reasonSCCUpdateFailed, err.Error())
+			return err
 		}
 	}
 
@@ -300,15 +375,30 @@ func (r *NginxIngressControllerReconciler) finalizeNginxIngressController(log lo
 	return nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// SetupWithManager sets up the controller with the Manager. The RBAC resources that cannot be owned by the
+// NginxIngressController are mapped to it with their owner labels, and the IngressClasses to the NginxIngressControllers that
+// use them. The cluster-scoped resources are not watched in namespaced mode.
 func (r *NginxIngressControllerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&k8sv1alpha1.NginxIngressController{}).
+	toOwner := handler.EnqueueRequestsFromMapFunc(requestsForOwnerLabels)
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&k8sv1beta1.NginxIngressController{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.DaemonSet{}).
 		Owns(&v1.ServiceAccount{}).
 		Owns(&v1.Service{}).
 		Owns(&v1.ConfigMap{}).
 		Owns(&v1.Secret{}).
-		Complete(r)
+		Watches(&source.Kind{Type: &rbacv1.Role{}}, toOwner).
+		Watches(&source.Kind{Type: &rbacv1.RoleBinding{}}, toOwner)
+	if !r.Namespaced {
+		b = b.Watches(&source.Kind{Type: &rbacv1.ClusterRole{}}, toOwner).
+			Watches(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}}, toOwner)
+		if hasIngressClassAPI() {
+			toIngressClassUsers := handler.EnqueueRequestsFromMapFunc(requestsForIngressClass(mgr.GetClient()))
+			b = b.Watches(&source.Kind{Type: &networking.IngressClass{}}, toIngressClassUsers).
+				Watches(&source.Kind{Type: &k8sv1beta1.IngressClassParameters{}}, toIngressClassUsers)
+		}
+	}
+	return b.Complete(r)
 }
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyPodCustomization sets the customization of the pods from the NginxIngressController spec on the pod template.
+// Extra labels and annotations are added to the ones of the template, the rest of the fields are replaced.
+func applyPodCustomization(template *corev1.PodTemplateSpec, instance *k8sv1beta1.NginxIngressController) {
+	pod := &k8sv1beta1.Pod{}
+	if instance.Spec.Pod != nil {
+		pod = instance.Spec.Pod
+	}
+
+	for k, v := range pod.ExtraLabels {
+		if template.Labels == nil {
+			template.Labels = map[string]string{}
+		}
+		template.Labels[k] = v
+	}
+	// The app label is used by the selector of the workload and the Service.
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels["app"] = instance.Name
+
+	for k, v := range pod.ExtraAnnotations {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[k] = v
+	}
+
+	// Empty maps are dropped, so the Operator doesn't take the ownership of empty limits or requests.
+	resources := *pod.Resources.DeepCopy()
+	if len(resources.Limits) == 0 {
+		resources.Limits = nil
+	}
+	if len(resources.Requests) == 0 {
+		resources.Requests = nil
+	}
+	// There is only 1 container in our template
+	template.Spec.Containers[0].Resources = resources
+
+	template.Spec.NodeSelector = nil
+	if len(pod.NodeSelector) > 0 {
+		template.Spec.NodeSelector = make(map[string]string, len(pod.NodeSelector))
+		for k, v := range pod.NodeSelector {
+			template.Spec.NodeSelector[k] = v
+		}
+	}
+
+	template.Spec.Tolerations = nil
+	for _, t := range pod.Tolerations {
+		template.Spec.Tolerations = append(template.Spec.Tolerations, *t.DeepCopy())
+	}
+
+	template.Spec.Affinity = pod.Affinity.DeepCopy()
+
+	template.Spec.TopologySpreadConstraints = nil
+	for _, c := range pod.TopologySpreadConstraints {
+		template.Spec.TopologySpreadConstraints = append(template.Spec.TopologySpreadConstraints, *c.DeepCopy())
+	}
+
+	template.Spec.PriorityClassName = pod.PriorityClassName
+}
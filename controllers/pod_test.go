@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyPodCustomization(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-nginx-ingress-controller",
+			Namespace: "my-nginx-ingress-controller",
+		},
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			Pod: &k8sv1beta1.Pod{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				},
+				NodeSelector: map[string]string{"node-role.kubernetes.io/edge": ""},
+				Tolerations: []corev1.Toleration{
+					{
+						Key:      "edge",
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+				},
+				PriorityClassName: "system-cluster-critical",
+				ExtraLabels:       map[string]string{"app": "other", "team": "ingress"},
+				ExtraAnnotations:  map[string]string{"prometheus.io/scrape": "true"},
+			},
+		},
+	}
+
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "my-nginx-ingress-controller"},
+			Annotations: map[string]string{"kubectl.kubernetes.io/restartedAt": "2021-01-01T00:00:00Z"},
+		},
+		Spec: corev1.PodSpec{
+			Containers:   []corev1.Container{{Name: "my-nginx-ingress-controller"}},
+			NodeSelector: map[string]string{"old": "selector"},
+		},
+	}
+
+	expected := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app": "my-nginx-ingress-controller", "team": "ingress"},
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/restartedAt": "2021-01-01T00:00:00Z",
+				"prometheus.io/scrape":              "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:      "my-nginx-ingress-controller",
+					Resources: instance.Spec.Pod.Resources,
+				},
+			},
+			NodeSelector:      map[string]string{"node-role.kubernetes.io/edge": ""},
+			Tolerations:       instance.Spec.Pod.Tolerations,
+			PriorityClassName: "system-cluster-critical",
+		},
+	}
+
+	applyPodCustomization(template, instance)
+	if diff := cmp.Diff(expected, template); diff != "" {
+		t.Errorf("applyPodCustomization() mismatch (-want +got):\n%s", diff)
+	}
+}
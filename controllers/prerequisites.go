@@ -7,154 +7,143 @@ import (
 	"github.com/nginxinc/nginx-ingress-operator/controllers/scc"
 
 	"github.com/go-logr/logr"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // checkPrerequisites creates all necessary objects before the deployment of a new Ingress Controller.
-func (r *NginxIngressControllerReconciler) checkPrerequisites(log logr.Logger, instance *k8sv1alpha1.NginxIngressController) error {
+func (r *NginxIngressControllerReconciler) checkPrerequisites(log logr.Logger, instance *k8sv1beta1.NginxIngressController) error {
 	sa, err := serviceAccountForNginxIngressController(instance, r.Scheme)
 	if err != nil {
 		return err
 	}
-	existed, err := r.createIfNotExists(sa)
-	if err != nil {
+	if err := r.apply(context.TODO(), instance, sa); err != nil {
 		return err
 	}
+	log.V(1).Info("ServiceAccount applied", "ServiceAccount.Namespace", sa.Namespace, "ServiceAccount.Name", sa.Name)
 
-	if !existed {
-		log.Info("ServiceAccount created", "ServiceAccount.Namespace", sa.Namespace, "ServiceAccount.Name", sa.Name)
-	}
-
-	// Assign this new ServiceAccount to the ClusterRoleBinding (if is not present already)
-	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+	return r.reconcileRBAC(context.TODO(), log, instance)
+}
 
-	err = r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName, Namespace: v1.NamespaceAll}, crb)
-	if err != nil {
+// reconcileRBAC grants the ServiceAccount of the NginxIngressController the rules required by the features it enables with a
+// ClusterRole and a ClusterRoleBinding of its own. When the Ingress Controller watches a single namespace, the ClusterRole only
+// has the cluster-scoped rules, and the rules for the namespaced resources are granted with Roles in the namespaces it uses.
+func (r *NginxIngressControllerReconciler) reconcileRBAC(ctx context.Context, log logr.Logger, instance *k8sv1beta1.NginxIngressController) error {
+	var namespaces []string
+	rules := rulesForNginxIngressController(instance)
+	if hasNamespacedRBAC(instance) {
+		var namespacedRules []rbacv1.PolicyRule
+		namespacedRules, rules = splitClusterScopedRules(rules)
+		namespaces = namespacesForNginxIngressController(instance)
+		if err := r.applyRoles(ctx, log, instance, namespaces, namespacedRules); err != nil {
+			return err
+		}
+	}
+	if err := r.deleteRoles(ctx, instance, namespaces); err != nil {
 		return err
 	}
+	// The ClusterRole and the ClusterRoleBinding are installed by the cluster administrator in namespaced mode.
+	if r.Namespaced {
+		return nil
+	}
 
-	subject := subjectForServiceAccount(sa.Namespace, sa.Name)
-	found := false
-	for _, s := range crb.Subjects {
-		if s.Name == subject.Name && s.Namespace == subject.Namespace {
-			found = true
-			break
-		}
+	cr := clusterRoleForNginxIngressController(instance, rules)
+	if err := r.apply(ctx, instance, cr); err != nil {
+		return err
 	}
+	if err := r.reconcileClusterRoleBinding(ctx, instance, cr.Name); err != nil {
+		return err
+	}
+	log.V(1).Info("ClusterRole and ClusterRoleBinding applied", "Name", cr.Name)
 
-	if !found {
-		crb.Subjects = append(crb.Subjects, subject)
+	return r.removeFromLegacyClusterRoleBinding(ctx, instance)
+}
 
-		err = r.Update(context.TODO(), crb)
-		if err != nil {
-			return err
-		}
-	}
+// reconcileClusterRoleBinding applies the ClusterRoleBinding of the NginxIngressController. The role of a ClusterRoleBinding
+// cannot be changed, so the ClusterRoleBinding is recreated when the ClusterRole changes.
+func (r *NginxIngressControllerReconciler) reconcileClusterRoleBinding(ctx context.Context, instance *k8sv1beta1.NginxIngressController, clusterRole string) error {
+	crb := clusterRoleBindingForInstance(instance, clusterRole)
 
-	// IngressClass is available from k8s 1.18+
-	minVersion, _ := version.ParseGeneric("v1.18.0")
-	if RunningK8sVersion.AtLeast(minVersion) {
-		ic := ingressClassForNginxIngressController(instance)
-		existed, err = r.createIfNotExists(ic)
-		if err != nil {
+	existing := &rbacv1.ClusterRoleBinding{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(crb), existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && existing.RoleRef != crb.RoleRef {
+		if err := r.deleteIfExists(ctx, instance, existing); err != nil {
 			return err
 		}
-
-		if !existed {
-			log.Info("IngressClass created", "IngressClass.Name", ic.Name)
-		}
 	}
 
-	if instance.Spec.DefaultSecret == "" {
-		err = r.Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, &v1.Secret{})
+	return r.apply(ctx, instance, crb)
+}
 
+// deleteClusterRoleAndBinding deletes the ClusterRole and the ClusterRoleBinding of the NginxIngressController.
+func (r *NginxIngressControllerReconciler) deleteClusterRoleAndBinding(ctx context.Context, instance *k8sv1beta1.NginxIngressController) error {
+	for _, obj := range []client.Object{&rbacv1.ClusterRoleBinding{}, &rbacv1.ClusterRole{}} {
+		err := r.Get(ctx, types.NamespacedName{Name: rbacName(instance)}, obj)
 		if err != nil && errors.IsNotFound(err) {
-			secret, err := defaultSecretForNginxIngressController(instance, r.Scheme)
-			if err != nil {
-				return err
-			}
-
-			err = r.Create(context.TODO(), secret)
-			if err != nil {
-				return err
-			}
-
-			log.Info("Warning! A custom self-signed TLS Secret has been created for the default server. "+
-				"Update your 'DefaultSecret' with your own Secret in Production",
-				"Secret.Namespace", secret.Namespace, "Secret.Name", secret.Name)
-
+			continue
 		} else if err != nil {
 			return err
 		}
-	}
-
-	if r.SccAPIExists {
-		err := scc.AddServiceAccount(r.Client, sa.Namespace, sa.Name)
-		if err != nil {
-			return fmt.Errorf("failed to add service account user to scc: %w", err)
+		if !isOwnedBy(obj, instance) {
+			continue
+		}
+		if err := r.deleteIfExists(ctx, instance, obj); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
-// create common resources shared by all the Ingress Controllers
-func (r *NginxIngressControllerReconciler) createCommonResources(log logr.Logger) error {
-	// Create ClusterRole and ClusterRoleBinding for all the NginxIngressController resources.
-	var err error
+// removeFromLegacyClusterRoleBinding removes the ServiceAccount of the NginxIngressController from the ClusterRoleBinding shared
+// by the Ingress Controllers in earlier versions of the Operator. The ClusterRoleBinding is removed by the
+// SharedResourcesReconciler once it has no subjects.
+func (r *NginxIngressControllerReconciler) removeFromLegacyClusterRoleBinding(ctx context.Context, instance *k8sv1beta1.NginxIngressController) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
 
-	cr := clusterRoleForNginxIngressController(clusterRoleName)
-
-	err = r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName, Namespace: v1.NamespaceAll}, cr)
+		err := r.Get(ctx, types.NamespacedName{Name: clusterRoleName, Namespace: v1.NamespaceAll}, crb)
+		if err != nil && errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
 
-	if err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("no previous ClusterRole found, creating a new one.")
-			err = r.Create(context.TODO(), cr)
-			if err != nil {
-				return fmt.Errorf("error creating ClusterRole: %w", err)
+		var subjects []rbacv1.Subject
+		for _, s := range crb.Subjects {
+			if s.Name != instance.Name || s.Namespace != instance.Namespace {
+				subjects = append(subjects, s)
 			}
-		} else {
-			return fmt.Errorf("error getting ClusterRole: %w", err)
 		}
-	} else {
-		// For updates in the ClusterRole permissions (eg new CRDs of the Ingress Controller).
-		log.Info("previous ClusterRole found, updating.")
-		cr := clusterRoleForNginxIngressController(clusterRoleName)
-		err = r.Update(context.TODO(), cr)
-		if err != nil {
-			return fmt.Errorf("error updating ClusterRole: %w", err)
+		if len(subjects) == len(crb.Subjects) {
+			return nil
 		}
-	}
 
-	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+		crb.Subjects = subjects
+		return r.Update(ctx, crb)
+	})
+}
 
-	err = r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName, Namespace: v1.NamespaceAll}, crb)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("no previous ClusterRoleBinding found, creating a new one.")
-		err = r.Create(context.TODO(), crb)
-	}
+// reconcileSCC creates the SecurityContextConstraints shared by all the Ingress Controllers on OpenShift
+// and adds the ServiceAccount of the Ingress Controller to its users.
+func (r *NginxIngressControllerReconciler) reconcileSCC(log logr.Logger, instance *k8sv1beta1.NginxIngressController) error {
+	log.Info("OpenShift detected as platform.")
 
+	err := scc.Create(r.Client, log)
 	if err != nil {
-		return fmt.Errorf("error creating ClusterRoleBinding: %w", err)
+		return fmt.Errorf("failed to create SecurityContextConstraints: %w", err)
 	}
 
-	err = createKICCustomResourceDefinitions(log, r.Mgr)
+	err = scc.AddServiceAccount(r.Client, instance.Namespace, instance.Name)
 	if err != nil {
-		return fmt.Errorf("error creating KIC CRDs: %w", err)
-	}
-
-	if r.SccAPIExists {
-		log.Info("OpenShift detected as platform.")
-
-		err := scc.Create(r.Client, log)
-		if err != nil {
-			return fmt.Errorf("failed to create SecurityContextConstraints: %w", err)
-		}
+		return fmt.Errorf("failed to add service account user to scc: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	defaultReadyStatusPort = 8081
+	readyStatusPath        = "/nginx-ready"
+	readinessPortName      = "readiness-port"
+)
+
+// The defaults of the probes. The startup probe gives NGINX up to 5 minutes to load its initial configuration
+// before the liveness probe takes over.
+var (
+	defaultReadinessProbe = corev1.Probe{InitialDelaySeconds: 0, PeriodSeconds: 1, TimeoutSeconds: 1, SuccessThreshold: 1, FailureThreshold: 3}
+	defaultLivenessProbe  = corev1.Probe{InitialDelaySeconds: 0, PeriodSeconds: 10, TimeoutSeconds: 5, SuccessThreshold: 1, FailureThreshold: 3}
+	defaultStartupProbe   = corev1.Probe{InitialDelaySeconds: 0, PeriodSeconds: 5, TimeoutSeconds: 1, SuccessThreshold: 1, FailureThreshold: 60}
+)
+
+// isReadyStatusEnabled returns whether the readiness endpoint and the probes are enabled. They are enabled by default.
+func isReadyStatusEnabled(instance *k8sv1beta1.NginxIngressController) bool {
+	rs := instance.Spec.ReadyStatus
+	return rs == nil || rs.Enable == nil || *rs.Enable
+}
+
+func readyStatusPort(instance *k8sv1beta1.NginxIngressController) int32 {
+	if instance.Spec.ReadyStatus != nil && instance.Spec.ReadyStatus.Port != nil {
+		return int32(*instance.Spec.ReadyStatus.Port)
+	}
+	return defaultReadyStatusPort
+}
+
+// containerPortsForNginxIngressController returns the ports of the Ingress Controller container.
+func containerPortsForNginxIngressController(instance *k8sv1beta1.NginxIngressController) []corev1.ContainerPort {
+	ports := []corev1.ContainerPort{
+		{
+			Name:          "http",
+			ContainerPort: 80,
+		},
+		{
+			Name:          "https",
+			ContainerPort: 443,
+		},
+	}
+
+	if isReadyStatusEnabled(instance) {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          readinessPortName,
+			ContainerPort: readyStatusPort(instance),
+		})
+	}
+
+	return ports
+}
+
+// generateProbe returns a probe of the readiness endpoint with the thresholds of the spec.
+// Every field is set, so the Operator owns the whole probe when the workload is applied.
+func generateProbe(defaults corev1.Probe, thresholds *k8sv1beta1.Probe) *corev1.Probe {
+	probe := defaults
+	probe.ProbeHandler = corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   readyStatusPath,
+			Port:   intstr.FromString(readinessPortName),
+			Scheme: corev1.URISchemeHTTP,
+		},
+	}
+
+	if thresholds == nil {
+		return &probe
+	}
+	if thresholds.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *thresholds.InitialDelaySeconds
+	}
+	if thresholds.PeriodSeconds != nil {
+		probe.PeriodSeconds = *thresholds.PeriodSeconds
+	}
+	if thresholds.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *thresholds.TimeoutSeconds
+	}
+	if thresholds.SuccessThreshold != nil {
+		probe.SuccessThreshold = *thresholds.SuccessThreshold
+	}
+	if thresholds.FailureThreshold != nil {
+		probe.FailureThreshold = *thresholds.FailureThreshold
+	}
+
+	return &probe
+}
+
+// applyProbes sets the readiness, liveness and startup probes of the Ingress Controller container.
+func applyProbes(container *corev1.Container, instance *k8sv1beta1.NginxIngressController) {
+	if !isReadyStatusEnabled(instance) {
+		container.ReadinessProbe = nil
+		container.LivenessProbe = nil
+		container.StartupProbe = nil
+		return
+	}
+
+	rs := instance.Spec.ReadyStatus
+	if rs == nil {
+		rs = &k8sv1beta1.ReadyStatus{}
+	}
+
+	container.ReadinessProbe = generateProbe(defaultReadinessProbe, rs.ReadinessProbe)
+	container.LivenessProbe = generateProbe(defaultLivenessProbe, rs.LivenessProbe)
+	container.StartupProbe = generateProbe(defaultStartupProbe, rs.StartupProbe)
+}
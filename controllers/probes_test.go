@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGenerateProbe(t *testing.T) {
+	period := int32(2)
+	failure := int32(10)
+
+	expected := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/nginx-ready",
+				Port:   intstr.FromString("readiness-port"),
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+		InitialDelaySeconds: 0,
+		PeriodSeconds:       2,
+		TimeoutSeconds:      1,
+		SuccessThreshold:    1,
+		FailureThreshold:    10,
+	}
+
+	result := generateProbe(defaultReadinessProbe, &k8sv1beta1.Probe{PeriodSeconds: &period, FailureThreshold: &failure})
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateProbe() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestContainerPortsForNginxIngressController(t *testing.T) {
+	port := uint16(9091)
+	disable := false
+
+	tests := []struct {
+		readyStatus *k8sv1beta1.ReadyStatus
+		expected    []int32
+		msg         string
+	}{
+		{
+			readyStatus: nil,
+			expected:    []int32{80, 443, 8081},
+			msg:         "default ready status",
+		},
+		{
+			readyStatus: &k8sv1beta1.ReadyStatus{Port: &port},
+			expected:    []int32{80, 443, 9091},
+			msg:         "custom ready status port",
+		},
+		{
+			readyStatus: &k8sv1beta1.ReadyStatus{Enable: &disable},
+			expected:    []int32{80, 443},
+			msg:         "ready status disabled",
+		},
+	}
+
+	for _, test := range tests {
+		instance := &k8sv1beta1.NginxIngressController{
+			Spec: k8sv1beta1.NginxIngressControllerSpec{ReadyStatus: test.readyStatus},
+		}
+		var result []int32
+		for _, p := range containerPortsForNginxIngressController(instance) {
+			result = append(result, p.ContainerPort)
+		}
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("containerPortsForNginxIngressController() mismatch (-want +got) for the case of %v:\n%s", test.msg, diff)
+		}
+	}
+}
@@ -1,11 +1,60 @@
 package controllers
 
 import (
+	"fmt"
+	"strings"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Labels of the RBAC resources of a NginxIngressController that cannot have an owner reference to it, because they are
+// cluster-scoped or in another namespace.
+const (
+	ownerNamespaceLabel = "k8s.nginx.org/owner-namespace"
+	ownerNameLabel      = "k8s.nginx.org/owner-name"
 )
 
-func clusterRoleForNginxIngressController(name string) *rbacv1.ClusterRole {
+// rbacName returns the name of the RBAC resources of the NginxIngressController. It includes the namespace of the
+// NginxIngressController, as the resources may be cluster-scoped or in a namespace shared with other NginxIngressControllers.
+func rbacName(instance *k8sv1beta1.NginxIngressController) string {
+	return fmt.Sprintf("nginx-ingress-%v-%v", instance.Namespace, instance.Name)
+}
+
+func ownerLabels(instance *k8sv1beta1.NginxIngressController) map[string]string {
+	return map[string]string{
+		ownerNamespaceLabel: instance.Namespace,
+		ownerNameLabel:      instance.Name,
+	}
+}
+
+// requestsForOwnerLabels maps a resource to the NginxIngressController in its owner labels.
+func requestsForOwnerLabels(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[ownerNamespaceLabel] == "" || labels[ownerNameLabel] == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: labels[ownerNamespaceLabel], Name: labels[ownerNameLabel]}}}
+}
+
+// isOwnedBy returns whether the labels of the resource refer to the NginxIngressController.
+func isOwnedBy(obj v1.Object, instance *k8sv1beta1.NginxIngressController) bool {
+	return obj.GetLabels()[ownerNamespaceLabel] == instance.Namespace && obj.GetLabels()[ownerNameLabel] == instance.Name
+}
+
+// rulesForNginxIngressController returns the rules the Ingress Controller needs for the features enabled in the
+// NginxIngressController, so it is not granted access to the resources it doesn't use.
+func rulesForNginxIngressController(instance *k8sv1beta1.NginxIngressController) []rbacv1.PolicyRule {
+	configMapVerbs := []string{"get", "list", "watch"}
+	if instance.Spec.EnableLeaderElection == nil || *instance.Spec.EnableLeaderElection {
+		// The leader election lock is a ConfigMap created and updated by the Ingress Controller.
+		configMapVerbs = append(configMapVerbs, "update", "create")
+	}
+
 	rules := []rbacv1.PolicyRule{
 		{
 			Verbs:     []string{"get", "list", "watch"},
@@ -18,7 +67,7 @@ func clusterRoleForNginxIngressController(name string) *rbacv1.ClusterRole {
 			Resources: []string{"secrets"},
 		},
 		{
-			Verbs:     []string{"get", "list", "watch", "update", "create"},
+			Verbs:     configMapVerbs,
 			APIGroups: []string{""},
 			Resources: []string{"configmaps"},
 		},
@@ -37,44 +86,73 @@ func clusterRoleForNginxIngressController(name string) *rbacv1.ClusterRole {
 			APIGroups: []string{"networking.k8s.io"},
 			Resources: []string{"ingresses"},
 		},
-		{
+	}
+	if instance.Spec.ReportIngressStatus != nil && instance.Spec.ReportIngressStatus.Enable {
+		rules = append(rules, rbacv1.PolicyRule{
 			Verbs:     []string{"update"},
 			APIGroups: []string{"networking.k8s.io"},
 			Resources: []string{"ingresses/status"},
-		},
-		{
-			Verbs:     []string{"get", "create"},
-			APIGroups: []string{"networking.k8s.io"},
-			Resources: []string{"ingressclasses"},
-		},
-		{
-			Verbs:     []string{"get", "list", "watch"},
-			APIGroups: []string{"k8s.nginx.org"},
-			Resources: []string{"virtualservers", "virtualserverroutes", "globalconfigurations", "transportservers", "policies"},
-		},
-		{
-			Verbs:     []string{"update"},
-			APIGroups: []string{"k8s.nginx.org"},
-			Resources: []string{"virtualservers/status", "virtualserverroutes/status", "policies/status", "transportservers/status"},
-		},
-		{
-			Verbs:     []string{"get", "list", "watch"},
-			APIGroups: []string{"appprotect.f5.com"},
-			Resources: []string{"aplogconfs", "appolicies", "apusersigs"},
-		},
-		{
+		})
+	}
+	rules = append(rules, rbacv1.PolicyRule{
+		Verbs:     []string{"get"},
+		APIGroups: []string{"networking.k8s.io"},
+		Resources: []string{"ingressclasses"},
+	})
+
+	return append(rules, customResourceRules(crdNamesForNginxIngressController(instance))...)
+}
+
+// customResourceRules returns the rules to read the custom resources of the CRDs, and to update the status of the
+// k8s.nginx.org custom resources that report it. The rules follow the order of the CRDs.
+func customResourceRules(crds []string) []rbacv1.PolicyRule {
+	var groups []string
+	resources := map[string][]string{}
+	for _, crd := range crds {
+		parts := strings.SplitN(crd, ".", 2)
+		resource, group := parts[0], parts[1]
+		if _, ok := resources[group]; !ok {
+			groups = append(groups, group)
+		}
+		if !containsString(resources[group], resource) {
+			resources[group] = append(resources[group], resource)
+		}
+	}
+
+	var rules []rbacv1.PolicyRule
+	for _, group := range groups {
+		rules = append(rules, rbacv1.PolicyRule{
 			Verbs:     []string{"get", "list", "watch"},
-			APIGroups: []string{"appprotectdos.f5.com"},
-			Resources: []string{"apdoslogconfs", "apdospolicies", "dosprotectedresources"},
-		},
+			APIGroups: []string{group},
+			Resources: resources[group],
+		})
+
+		var status []string
+		for _, resource := range resources[group] {
+			if group == "k8s.nginx.org" && resource != "globalconfigurations" {
+				status = append(status, resource+"/status")
+			}
+		}
+		if len(status) > 0 {
+			rules = append(rules, rbacv1.PolicyRule{
+				Verbs:     []string{"update"},
+				APIGroups: []string{group},
+				Resources: status,
+			})
+		}
 	}
-	rbac := &rbacv1.ClusterRole{
+	return rules
+}
+
+// clusterRoleForNginxIngressController returns the ClusterRole of the NginxIngressController with the rules.
+func clusterRoleForNginxIngressController(instance *k8sv1beta1.NginxIngressController, rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
 		ObjectMeta: v1.ObjectMeta{
-			Name: name,
+			Name:   rbacName(instance),
+			Labels: ownerLabels(instance),
 		},
 		Rules: rules,
 	}
-	return rbac
 }
 
 func subjectForServiceAccount(namespace string, name string) rbacv1.Subject {
@@ -86,6 +164,8 @@ func subjectForServiceAccount(namespace string, name string) rbacv1.Subject {
 	return sa
 }
 
+// clusterRoleBindingForNginxIngressController returns the ClusterRoleBinding shared by the Ingress Controllers in earlier versions
+// of the Operator. It is only used to remove the legacy subjects.
 func clusterRoleBindingForNginxIngressController(name string) *rbacv1.ClusterRoleBinding {
 	crb := &rbacv1.ClusterRoleBinding{
 		ObjectMeta: v1.ObjectMeta{
@@ -99,3 +179,20 @@ func clusterRoleBindingForNginxIngressController(name string) *rbacv1.ClusterRol
 	}
 	return crb
 }
+
+// clusterRoleBindingForInstance returns the ClusterRoleBinding that binds the ClusterRole to the ServiceAccount of the
+// NginxIngressController.
+func clusterRoleBindingForInstance(instance *k8sv1beta1.NginxIngressController, clusterRole string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   rbacName(instance),
+			Labels: ownerLabels(instance),
+		},
+		Subjects: []rbacv1.Subject{subjectForServiceAccount(instance.Namespace, instance.Name)},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+}
@@ -1,86 +1,158 @@
 package controllers
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-func TestClusterRoleForNginxIngressController(t *testing.T) {
-	name := "my-rcluster-role"
-	expected := &rbacv1.ClusterRole{
-		ObjectMeta: v1.ObjectMeta{
-			Name: name,
+func TestRulesForNginxIngressController(t *testing.T) {
+	baseRules := []rbacv1.PolicyRule{
+		{
+			Verbs:     []string{"get", "list", "watch"},
+			APIGroups: []string{""},
+			Resources: []string{"services", "endpoints"},
 		},
-		Rules: []rbacv1.PolicyRule{
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{""},
-				Resources: []string{"services", "endpoints"},
-			},
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{""},
-				Resources: []string{"secrets"},
-			},
-			{
-				Verbs:     []string{"get", "list", "watch", "update", "create"},
-				APIGroups: []string{""},
-				Resources: []string{"configmaps"},
-			},
-			{
-				Verbs:     []string{"list", "watch"},
-				APIGroups: []string{""},
-				Resources: []string{"pods"},
-			},
-			{
-				Verbs:     []string{"create", "patch"},
-				APIGroups: []string{""},
-				Resources: []string{"events"},
-			},
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{"networking.k8s.io"},
-				Resources: []string{"ingresses"},
-			},
-			{
-				Verbs:     []string{"update"},
-				APIGroups: []string{"networking.k8s.io"},
-				Resources: []string{"ingresses/status"},
-			},
-			{
-				Verbs:     []string{"get", "create"},
-				APIGroups: []string{"networking.k8s.io"},
-				Resources: []string{"ingressclasses"},
-			},
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{"k8s.nginx.org"},
-				Resources: []string{"virtualservers", "virtualserverroutes", "globalconfigurations", "transportservers", "policies"},
-			},
-			{
-				Verbs:     []string{"update"},
-				APIGroups: []string{"k8s.nginx.org"},
-				Resources: []string{"virtualservers/status", "virtualserverroutes/status", "policies/status", "transportservers/status"},
+		{
+			Verbs:     []string{"get", "list", "watch"},
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+		},
+	}
+	podRules := []rbacv1.PolicyRule{
+		{
+			Verbs:     []string{"list", "watch"},
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+		},
+		{
+			Verbs:     []string{"create", "patch"},
+			APIGroups: []string{""},
+			Resources: []string{"events"},
+		},
+		{
+			Verbs:     []string{"get", "list", "watch"},
+			APIGroups: []string{"networking.k8s.io"},
+			Resources: []string{"ingresses"},
+		},
+	}
+	ingressClassRule := rbacv1.PolicyRule{
+		Verbs:     []string{"get"},
+		APIGroups: []string{"networking.k8s.io"},
+		Resources: []string{"ingressclasses"},
+	}
+	rulesFor := func(configMapVerbs []string, rules ...rbacv1.PolicyRule) []rbacv1.PolicyRule {
+		result := append([]rbacv1.PolicyRule{}, baseRules...)
+		result = append(result, rbacv1.PolicyRule{Verbs: configMapVerbs, APIGroups: []string{""}, Resources: []string{"configmaps"}})
+		result = append(result, podRules...)
+		return append(result, rules...)
+	}
+	disabled := false
+
+	tests := []struct {
+		spec     k8sv1beta1.NginxIngressControllerSpec
+		expected []rbacv1.PolicyRule
+		msg      string
+	}{
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{},
+			expected: rulesFor([]string{"get", "list", "watch", "update", "create"},
+				ingressClassRule,
+				rbacv1.PolicyRule{
+					Verbs:     []string{"get", "list", "watch"},
+					APIGroups: []string{"k8s.nginx.org"},
+					Resources: []string{"virtualservers", "virtualserverroutes", "transportservers", "policies"},
+				},
+				rbacv1.PolicyRule{
+					Verbs:     []string{"update"},
+					APIGroups: []string{"k8s.nginx.org"},
+					Resources: []string{"virtualservers/status", "virtualserverroutes/status", "transportservers/status", "policies/status"},
+				},
+			),
+			msg: "default features",
+		},
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{
+				EnableCRDs:           &disabled,
+				EnableLeaderElection: &disabled,
 			},
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{"appprotect.f5.com"},
-				Resources: []string{"aplogconfs", "appolicies", "apusersigs"},
+			expected: rulesFor([]string{"get", "list", "watch"}, ingressClassRule),
+			msg:      "CRDs and leader election disabled",
+		},
+		{
+			spec: k8sv1beta1.NginxIngressControllerSpec{
+				ReportIngressStatus: &k8sv1beta1.ReportIngressStatus{Enable: true},
+				GlobalConfiguration: &k8sv1beta1.ObjectReference{Namespace: "default", Name: "gc"},
+				AppProtect:          &k8sv1beta1.AppProtect{Enable: true},
+				AppProtectDos:       &k8sv1beta1.AppProtectDos{Enable: true},
 			},
-			{
-				Verbs:     []string{"get", "list", "watch"},
-				APIGroups: []string{"appprotectdos.f5.com"},
-				Resources: []string{"apdoslogconfs", "apdospolicies", "dosprotectedresources"},
+			expected: rulesFor([]string{"get", "list", "watch", "update", "create"},
+				rbacv1.PolicyRule{
+					Verbs:     []string{"update"},
+					APIGroups: []string{"networking.k8s.io"},
+					Resources: []string{"ingresses/status"},
+				},
+				ingressClassRule,
+				rbacv1.PolicyRule{
+					Verbs:     []string{"get", "list", "watch"},
+					APIGroups: []string{"k8s.nginx.org"},
+					Resources: []string{"virtualservers", "virtualserverroutes", "transportservers", "policies", "globalconfigurations"},
+				},
+				rbacv1.PolicyRule{
+					Verbs:     []string{"update"},
+					APIGroups: []string{"k8s.nginx.org"},
+					Resources: []string{"virtualservers/status", "virtualserverroutes/status", "transportservers/status", "policies/status"},
+				},
+				rbacv1.PolicyRule{
+					Verbs:     []string{"get", "list", "watch"},
+					APIGroups: []string{"appprotect.f5.com"},
+					Resources: []string{"aplogconfs", "appolicies", "apusersigs"},
+				},
+				rbacv1.PolicyRule{
+					Verbs:     []string{"get", "list", "watch"},
+					APIGroups: []string{"appprotectdos.f5.com"},
+					Resources: []string{"apdoslogconfs", "apdospolicies", "dosprotectedresources"},
+				},
+			),
+			msg: "all features enabled",
+		},
+	}
+
+	for _, test := range tests {
+		instance := &k8sv1beta1.NginxIngressController{Spec: test.spec}
+		if diff := cmp.Diff(test.expected, rulesForNginxIngressController(instance)); diff != "" {
+			t.Errorf("rulesForNginxIngressController() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestClusterRoleForNginxIngressController(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{ObjectMeta: v1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default"}}
+	rules := []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}}}
+	expected := &rbacv1.ClusterRole{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "nginx-ingress-default-my-nginx-ingress",
+			Labels: map[string]string{
+				"k8s.nginx.org/owner-namespace": "default",
+				"k8s.nginx.org/owner-name":      "my-nginx-ingress",
 			},
 		},
+		Rules: rules,
 	}
 
-	result := clusterRoleForNginxIngressController(name)
+	result := clusterRoleForNginxIngressController(instance, rules)
 	if diff := cmp.Diff(expected, result); diff != "" {
-		t.Errorf("clusterRoleForNginxIngressController(%v) mismatch (-want +got):\n%s", name, diff)
+		t.Errorf("clusterRoleForNginxIngressController() mismatch (-want +got):\n%s", diff)
 	}
 }
 
@@ -117,3 +189,126 @@ func TestClusterRoleBindingForNginxIngressController(t *testing.T) {
 		t.Errorf("clusterRoleBindingForNginxIngressController(%v) mismatch (-want +got):\n%s", name, diff)
 	}
 }
+
+func TestClusterRoleBindingForInstance(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{ObjectMeta: v1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default"}}
+	expected := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "nginx-ingress-default-my-nginx-ingress",
+			Labels: map[string]string{
+				"k8s.nginx.org/owner-namespace": "default",
+				"k8s.nginx.org/owner-name":      "my-nginx-ingress",
+			},
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      "my-nginx-ingress",
+				Namespace: "default",
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     clusterRoleName,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+
+	result := clusterRoleBindingForInstance(instance, clusterRoleName)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("clusterRoleBindingForInstance(%v) mismatch (-want +got):\n%s", clusterRoleName, diff)
+	}
+}
+
+func TestDeleteClusterRoleAndBinding(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{ObjectMeta: v1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default"}}
+
+	tests := []struct {
+		objects  []client.Object
+		expected bool
+		msg      string
+	}{
+		{
+			objects:  nil,
+			expected: false,
+			msg:      "no ClusterRole and ClusterRoleBinding",
+		},
+		{
+			objects: []client.Object{
+				clusterRoleForNginxIngressController(instance, nil),
+				clusterRoleBindingForInstance(instance, rbacName(instance)),
+			},
+			expected: false,
+			msg:      "ClusterRole and ClusterRoleBinding of the NginxIngressController",
+		},
+		{
+			objects: []client.Object{
+				&rbacv1.ClusterRole{ObjectMeta: v1.ObjectMeta{Name: rbacName(instance)}},
+				&rbacv1.ClusterRoleBinding{ObjectMeta: v1.ObjectMeta{Name: rbacName(instance)}},
+			},
+			expected: true,
+			msg:      "ClusterRole and ClusterRoleBinding not managed by the Operator",
+		},
+	}
+
+	for _, test := range tests {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(test.objects...).Build()
+		r := &NginxIngressControllerReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(10)}
+
+		if err := r.deleteClusterRoleAndBinding(context.Background(), instance); err != nil {
+			t.Fatalf("deleteClusterRoleAndBinding() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		for _, obj := range []client.Object{&rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{}} {
+			err := c.Get(context.Background(), client.ObjectKey{Name: rbacName(instance)}, obj)
+			if exists := err == nil; exists != test.expected {
+				t.Errorf("deleteClusterRoleAndBinding() left the %T %v but expected %v for the case of %v", obj, exists, test.expected, test.msg)
+			}
+			if err != nil && !errors.IsNotFound(err) {
+				t.Errorf("failed to get the %T: %v", obj, err)
+			}
+		}
+	}
+}
+
+func TestRemoveFromLegacyClusterRoleBinding(t *testing.T) {
+	s := newCATestScheme(t)
+	instance := &k8sv1beta1.NginxIngressController{ObjectMeta: v1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"}}
+
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &NginxIngressControllerReconciler{Client: c, Scheme: s}
+	if err := r.removeFromLegacyClusterRoleBinding(context.Background(), instance); err != nil {
+		t.Errorf("removeFromLegacyClusterRoleBinding() returned unexpected error %v when the ClusterRoleBinding doesn't exist", err)
+	}
+
+	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+	crb.Subjects = []rbacv1.Subject{
+		subjectForServiceAccount("other", "other"),
+		subjectForServiceAccount(instance.Namespace, instance.Name),
+	}
+	c = fake.NewClientBuilder().WithScheme(s).WithObjects(crb).Build()
+	r = &NginxIngressControllerReconciler{Client: c, Scheme: s}
+	if err := r.removeFromLegacyClusterRoleBinding(context.Background(), instance); err != nil {
+		t.Fatalf("removeFromLegacyClusterRoleBinding() returned unexpected error %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(crb), crb); err != nil {
+		t.Fatalf("failed to get the ClusterRoleBinding: %v", err)
+	}
+	if diff := cmp.Diff([]rbacv1.Subject{subjectForServiceAccount("other", "other")}, crb.Subjects); diff != "" {
+		t.Errorf("removeFromLegacyClusterRoleBinding() subjects mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequestsForOwnerLabels(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{ObjectMeta: v1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default"}}
+
+	result := requestsForOwnerLabels(clusterRoleBindingForInstance(instance, clusterRoleName))
+	expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "my-nginx-ingress"}}}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("requestsForOwnerLabels() mismatch (-want +got):\n%s", diff)
+	}
+
+	if result := requestsForOwnerLabels(clusterRoleBindingForNginxIngressController(clusterRoleName)); result != nil {
+		t.Errorf("requestsForOwnerLabels() returned %v but expected no requests for a resource without owner labels", result)
+	}
+}
@@ -67,6 +67,7 @@ func Create(client client.Client, log logr.Logger) error {
 			if err != nil {
 				return fmt.Errorf("error creating SecurityContextConstraints: %w", err)
 			}
+			return nil
 		}
 		return fmt.Errorf("error getting scc: %w", err)
 	}
@@ -98,6 +99,26 @@ func AddServiceAccount(client client.Client, namespace string, name string) erro
 	return nil
 }
 
+// HasServiceAccount returns whether the SecurityContextConstraints exists and allows the service account to use it.
+func HasServiceAccount(reader client.Reader, namespace string, name string) (bool, error) {
+	scc := sccConfigTemplate()
+	err := reader.Get(context.TODO(), types.NamespacedName{Name: defaultName, Namespace: v1.NamespaceAll}, scc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get scc: %w", err)
+	}
+
+	saName := serviceAccountName(namespace, name)
+	for _, u := range scc.Users {
+		if u == saName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func RemoveServiceAccount(client client.Client, namespace string, name string) error {
 	scc := sccConfigTemplate()
 	err := client.Get(context.TODO(), types.NamespacedName{Name: defaultName, Namespace: v1.NamespaceAll}, scc)
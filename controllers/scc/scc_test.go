@@ -1,13 +1,18 @@
 package scc
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	secv1 "github.com/openshift/api/security/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestSccForNginxIngressController(t *testing.T) {
@@ -62,3 +67,86 @@ func TestServiceAccountName(t *testing.T) {
 		t.Errorf("serviceAccountName(%v, %v) returned %v but expected %v", namespace, name, result, expected)
 	}
 }
+
+func TestHasServiceAccount(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := secv1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add the SCC API to the scheme: %v", err)
+	}
+	withUser := sccConfigTemplate()
+	withUser.Users = []string{serviceAccountName("my-nginx-ingress", "my-nginx-ingress")}
+
+	tests := []struct {
+		objects  []client.Object
+		expected bool
+		msg      string
+	}{
+		{
+			objects:  nil,
+			expected: false,
+			msg:      "no SecurityContextConstraints",
+		},
+		{
+			objects:  []client.Object{sccConfigTemplate()},
+			expected: false,
+			msg:      "SecurityContextConstraints without the service account",
+		},
+		{
+			objects:  []client.Object{withUser},
+			expected: true,
+			msg:      "SecurityContextConstraints with the service account",
+		},
+	}
+
+	for _, test := range tests {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(test.objects...).Build()
+		result, err := HasServiceAccount(c, "my-nginx-ingress", "my-nginx-ingress")
+		if err != nil {
+			t.Fatalf("HasServiceAccount() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		if result != test.expected {
+			t.Errorf("HasServiceAccount() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := secv1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add the SCC API to the scheme: %v", err)
+	}
+	withUser := sccConfigTemplate()
+	withUser.Users = []string{serviceAccountName("my-nginx-ingress", "my-nginx-ingress")}
+
+	tests := []struct {
+		objects       []client.Object
+		expectedUsers []string
+		msg           string
+	}{
+		{
+			objects:       nil,
+			expectedUsers: nil,
+			msg:           "missing SecurityContextConstraints created",
+		},
+		{
+			objects:       []client.Object{withUser},
+			expectedUsers: withUser.Users,
+			msg:           "existing SecurityContextConstraints kept",
+		},
+	}
+
+	for _, test := range tests {
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(test.objects...).Build()
+		if err := Create(c, logr.Discard()); err != nil {
+			t.Fatalf("Create() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+
+		result := &secv1.SecurityContextConstraints{}
+		if err := c.Get(context.Background(), client.ObjectKey{Name: defaultName}, result); err != nil {
+			t.Fatalf("failed to get the SecurityContextConstraints for the case of %v: %v", test.msg, err)
+		}
+		if diff := cmp.Diff(test.expectedUsers, result.Users); diff != "" {
+			t.Errorf("Create() users mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
@@ -1,34 +1,108 @@
 package controllers
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net"
 	"time"
 
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-const sslHost = "example.com"
+// defaultCertificateHashAnnotation stores the hash of the parameters of a certificate generated by the Operator,
+// so the certificate is regenerated when they change.
+const defaultCertificateHashAnnotation = "k8s.nginx.org/default-certificate-hash"
 
-func defaultSecretForNginxIngressController(instance *k8sv1alpha1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Secret, error) {
-	crt, key, err := generateFakeCertAndKey()
+// certificateParams are the parameters of a generated certificate.
+type certificateParams struct {
+	CommonName   string                  `json:"commonName"`
+	DNSNames     []string                `json:"dnsNames"`
+	IPAddresses  []string                `json:"ipAddresses,omitempty"`
+	KeyAlgorithm k8sv1beta1.KeyAlgorithm `json:"keyAlgorithm"`
+	Duration     time.Duration           `json:"duration"`
+	// Issuer identifies the CA that signs the certificate. Empty for a self-signed certificate.
+	Issuer string `json:"issuer,omitempty"`
+}
+
+// defaultCertificateParams returns the parameters of the default certificate from the spec, with the defaults for the unset fields.
+func defaultCertificateParams(instance *k8sv1beta1.NginxIngressController) certificateParams {
+	params := certificateParams{
+		CommonName:   k8sv1beta1.DefaultCertificateCommonName,
+		KeyAlgorithm: k8sv1beta1.KeyAlgorithmRSA2048,
+		Duration:     k8sv1beta1.DefaultCertificateDuration,
+	}
+
+	if dc := instance.Spec.DefaultCertificate; dc != nil {
+		if dc.CommonName != "" {
+			params.CommonName = dc.CommonName
+		}
+		params.DNSNames = dc.DNSNames
+		params.IPAddresses = dc.IPAddresses
+		if dc.KeyAlgorithm != "" {
+			params.KeyAlgorithm = dc.KeyAlgorithm
+		}
+		if dc.Duration != nil {
+			params.Duration = dc.Duration.Duration
+		}
+	}
+
+	if len(params.DNSNames) == 0 {
+		params.DNSNames = []string{params.CommonName}
+	}
+
+	return params
+}
+
+// hash returns a hash of the parameters, used to detect changes.
+func (p certificateParams) hash() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// issuedBy returns the parameters of a certificate signed by the CA, or of a self-signed certificate when ca is nil.
+func (p certificateParams) issuedBy(ca *caKeyPair) certificateParams {
+	p.Issuer = ""
+	if ca != nil {
+		p.Issuer = ca.id()
+	}
+	return p
+}
+
+func defaultSecretForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Secret, error) {
+	return certificateSecretForNginxIngressController(instance, scheme, instance.Name, defaultCertificateParams(instance), nil)
+}
+
+// certificateSecretForNginxIngressController returns a TLS Secret with a certificate for the parameters, signed by the CA
+// or self-signed when ca is nil. The Secret of a certificate signed by the CA also contains the CA certificate.
+func certificateSecretForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme, name string, params certificateParams, ca *caKeyPair) (*corev1.Secret, error) {
+	params = params.issuedBy(ca)
+	crt, key, err := generateCertificate(params, ca)
 	if err != nil {
 		return nil, err
 	}
 
 	secret := &corev1.Secret{
 		ObjectMeta: v1.ObjectMeta{
-			Name:      instance.Name,
-			Namespace: instance.Namespace,
+			Name:        name,
+			Namespace:   instance.Namespace,
+			Annotations: map[string]string{defaultCertificateHashAnnotation: params.hash()},
 		},
 		Data: map[string][]byte{
 			corev1.TLSCertKey:       crt,
@@ -36,6 +110,9 @@ func defaultSecretForNginxIngressController(instance *k8sv1alpha1.NginxIngressCo
 		},
 		Type: corev1.SecretTypeTLS,
 	}
+	if ca != nil {
+		secret.Data[caCertKey] = ca.certPEM
+	}
 	err = ctrl.SetControllerReference(instance, secret, scheme)
 	if err != nil {
 		return nil, err
@@ -43,17 +120,58 @@ func defaultSecretForNginxIngressController(instance *k8sv1alpha1.NginxIngressCo
 	return secret, nil
 }
 
-func generateFakeCertAndKey() ([]byte, []byte, error) {
-	var priv interface{}
+// generatePrivateKey returns a new private key of the algorithm and its PEM encoding.
+func generatePrivateKey(algorithm k8sv1beta1.KeyAlgorithm) (crypto.Signer, []byte, error) {
+	switch algorithm {
+	case k8sv1beta1.KeyAlgorithmRSA2048, k8sv1beta1.KeyAlgorithmRSA4096:
+		bits := 2048
+		if algorithm == k8sv1beta1.KeyAlgorithmRSA4096 {
+			bits = 4096
+		}
+		priv, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}), nil
+	case k8sv1beta1.KeyAlgorithmECDSAP256, k8sv1beta1.KeyAlgorithmECDSAP384:
+		curve := elliptic.P256()
+		if algorithm == k8sv1beta1.KeyAlgorithmECDSAP384 {
+			curve = elliptic.P384()
+		}
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(priv)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	case k8sv1beta1.KeyAlgorithmEd25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
 
-	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	return nil, nil, fmt.Errorf("unsupported key algorithm %q", algorithm)
+}
+
+// generateCertificate returns a PEM encoded certificate and key for the parameters, signed by the CA or self-signed when ca is nil.
+// A certificate signed by the CA doesn't outlive the CA certificate.
+func generateCertificate(params certificateParams, ca *caKeyPair) ([]byte, []byte, error) {
+	priv, key, err := generatePrivateKey(params.KeyAlgorithm)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	notBefore := time.Now()
-	// This certificate is valid for 365 days
-	notAfter := notBefore.Add(365 * 24 * time.Hour)
+	notAfter := notBefore.Add(params.Duration)
 
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
@@ -61,27 +179,45 @@ func generateFakeCertAndKey() ([]byte, []byte, error) {
 		return nil, nil, err
 	}
 
+	// Key encipherment only applies to RSA keys.
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := priv.(*rsa.PrivateKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
 	template := x509.Certificate{
 		SerialNumber: serialNumber,
 		Subject: pkix.Name{
 			Organization: []string{"NGINX Inc"},
-			CommonName:   "example.com",
+			CommonName:   params.CommonName,
 		},
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		KeyUsage:              keyUsage,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
-		DNSNames:              []string{sslHost},
+		DNSNames:              params.DNSNames,
+	}
+	for _, ip := range params.IPAddresses {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			template.IPAddresses = append(template.IPAddresses, parsed)
+		}
+	}
+
+	parent, signer := &template, crypto.Signer(priv)
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+		if template.NotAfter.After(ca.cert.NotAfter) {
+			template.NotAfter = ca.cert.NotAfter
+		}
 	}
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.(*rsa.PrivateKey).PublicKey, priv)
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, parent, priv.Public(), signer)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
-	key := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv.(*rsa.PrivateKey))})
 
 	if cert == nil || key == nil {
 		return nil, nil, fmt.Errorf("error encoding ket/crt to PEM")
@@ -89,3 +225,22 @@ func generateFakeCertAndKey() ([]byte, []byte, error) {
 
 	return cert, key, nil
 }
+
+// certificateNotAfter returns the expiry time of the first certificate of the PEM encoded data.
+func certificateNotAfter(data []byte) (time.Time, error) {
+	cert, err := parseCertificate(data)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return cert.NotAfter, nil
+}
+
+// parseCertificate parses a PEM encoded certificate.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"reflect"
 	"testing"
+	"time"
 
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -13,10 +17,10 @@ import (
 func TestDefaultSecretForNginxIngressController(t *testing.T) {
 	s := scheme.Scheme
 
-	if err := k8sv1alpha1.AddToScheme(s); err != nil {
-		t.Fatalf("Unable to add k8sv1alpha1 scheme: (%v)", err)
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
 	}
-	instance := &k8sv1alpha1.NginxIngressController{
+	instance := &k8sv1beta1.NginxIngressController{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "my-nginx-ingress-controller",
 			Namespace: "my-nginx-ingress-controller-ns",
@@ -47,3 +51,126 @@ func TestDefaultSecretForNginxIngressController(t *testing.T) {
 		t.Errorf("defaultSecretForNginxIngressController() returned empty data key %s", corev1.TLSPrivateKeyKey)
 	}
 }
+
+func TestCertificateNotAfter(t *testing.T) {
+	crt, _, err := generateCertificate(defaultCertificateParams(&k8sv1beta1.NginxIngressController{}), nil)
+	if err != nil {
+		t.Fatalf("generateCertificate() returned unexpected error %v", err)
+	}
+
+	notAfter, err := certificateNotAfter(crt)
+	if err != nil {
+		t.Fatalf("certificateNotAfter() returned unexpected error %v", err)
+	}
+	if days := time.Until(notAfter).Hours() / 24; days < 364 || days > 365 {
+		t.Errorf("certificateNotAfter() returned %v, %v days from now, but expected 365 days", notAfter, days)
+	}
+
+	if _, err := certificateNotAfter([]byte("invalid")); err == nil {
+		t.Errorf("certificateNotAfter() returned no error for invalid data")
+	}
+}
+
+func TestGenerateSelfSignedCertificate(t *testing.T) {
+	tests := []struct {
+		params              certificateParams
+		expectedKeyType     string
+		expectedKeyUsage    x509.KeyUsage
+		expectedIPAddresses int
+		msg                 string
+	}{
+		{
+			params:           defaultCertificateParams(&k8sv1beta1.NginxIngressController{}),
+			expectedKeyType:  "RSA PRIVATE KEY",
+			expectedKeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			msg:              "default parameters",
+		},
+		{
+			params: certificateParams{
+				CommonName:   "ingress.example.com",
+				DNSNames:     []string{"ingress.example.com", "*.example.com"},
+				IPAddresses:  []string{"10.0.0.1", "2001:db8::1"},
+				KeyAlgorithm: k8sv1beta1.KeyAlgorithmECDSAP256,
+				Duration:     90 * 24 * time.Hour,
+			},
+			expectedKeyType:     "EC PRIVATE KEY",
+			expectedKeyUsage:    x509.KeyUsageDigitalSignature,
+			expectedIPAddresses: 2,
+			msg:                 "ECDSA key with SANs",
+		},
+		{
+			params: certificateParams{
+				CommonName:   "ingress.example.com",
+				DNSNames:     []string{"ingress.example.com"},
+				KeyAlgorithm: k8sv1beta1.KeyAlgorithmEd25519,
+				Duration:     24 * time.Hour,
+			},
+			expectedKeyType:  "PRIVATE KEY",
+			expectedKeyUsage: x509.KeyUsageDigitalSignature,
+			msg:              "Ed25519 key",
+		},
+	}
+
+	for _, test := range tests {
+		crt, key, err := generateCertificate(test.params, nil)
+		if err != nil {
+			t.Fatalf("generateCertificate() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		if _, err := tls.X509KeyPair(crt, key); err != nil {
+			t.Errorf("generateCertificate() returned a certificate and key that don't match for the case of %v: %v", test.msg, err)
+		}
+		if block, _ := pem.Decode(key); block == nil || block.Type != test.expectedKeyType {
+			t.Errorf("generateCertificate() returned a key that is not a %v for the case of %v", test.expectedKeyType, test.msg)
+		}
+
+		block, _ := pem.Decode(crt)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("failed to parse the certificate for the case of %v: %v", test.msg, err)
+		}
+		if cert.Subject.CommonName != test.params.CommonName {
+			t.Errorf("generateCertificate() returned the common name %v but expected %v for the case of %v", cert.Subject.CommonName, test.params.CommonName, test.msg)
+		}
+		if !reflect.DeepEqual(cert.DNSNames, test.params.DNSNames) {
+			t.Errorf("generateCertificate() returned the DNS names %v but expected %v for the case of %v", cert.DNSNames, test.params.DNSNames, test.msg)
+		}
+		if len(cert.IPAddresses) != test.expectedIPAddresses {
+			t.Errorf("generateCertificate() returned the IP addresses %v but expected %v for the case of %v", cert.IPAddresses, test.params.IPAddresses, test.msg)
+		}
+		if cert.KeyUsage != test.expectedKeyUsage {
+			t.Errorf("generateCertificate() returned the key usage %v but expected %v for the case of %v", cert.KeyUsage, test.expectedKeyUsage, test.msg)
+		}
+		if validity := cert.NotAfter.Sub(cert.NotBefore); validity != test.params.Duration {
+			t.Errorf("generateCertificate() returned a certificate valid for %v but expected %v for the case of %v", validity, test.params.Duration, test.msg)
+		}
+	}
+}
+
+func TestDefaultCertificateParams(t *testing.T) {
+	defaults := defaultCertificateParams(&k8sv1beta1.NginxIngressController{})
+	if !reflect.DeepEqual(defaults.DNSNames, []string{k8sv1beta1.DefaultCertificateCommonName}) {
+		t.Errorf("defaultCertificateParams() returned the DNS names %v but expected the common name", defaults.DNSNames)
+	}
+
+	renewBeforeOnly := &k8sv1beta1.NginxIngressController{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			DefaultCertificate: &k8sv1beta1.DefaultCertificate{RenewBefore: &metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	if defaultCertificateParams(renewBeforeOnly).hash() != defaults.hash() {
+		t.Errorf("defaultCertificateParams() returned a different hash when only renewBefore is set")
+	}
+
+	commonName := &k8sv1beta1.NginxIngressController{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
+			DefaultCertificate: &k8sv1beta1.DefaultCertificate{CommonName: "ingress.example.com"},
+		},
+	}
+	params := defaultCertificateParams(commonName)
+	if !reflect.DeepEqual(params.DNSNames, []string{"ingress.example.com"}) {
+		t.Errorf("defaultCertificateParams() returned the DNS names %v but expected the common name", params.DNSNames)
+	}
+	if params.hash() == defaults.hash() {
+		t.Errorf("defaultCertificateParams() returned the same hash for a different common name")
+	}
+}
@@ -1,16 +1,15 @@
 package controllers
 
 import (
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func serviceForNginxIngressController(instance *k8sv1alpha1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Service, error) {
+func serviceForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Service, error) {
 	extraLabels := map[string]string{}
 	extraAnnotations := map[string]string{}
 	if instance.Spec.Service != nil {
@@ -57,12 +56,3 @@ func serviceForNginxIngressController(instance *k8sv1alpha1.NginxIngressControll
 
 	return svc, nil
 }
-
-func serviceMutateFn(svc *corev1.Service, serviceType string, labels map[string]string, annotations map[string]string) controllerutil.MutateFn {
-	return func() error {
-		svc.Spec.Type = corev1.ServiceType(serviceType)
-		svc.Labels = labels
-		svc.Annotations = annotations
-		return nil
-	}
-}
@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -15,24 +15,24 @@ func TestServiceForNginxIngressController(t *testing.T) {
 	boolPointer := func(b bool) *bool { return &b }
 	s := scheme.Scheme
 
-	if err := k8sv1alpha1.AddToScheme(s); err != nil {
-		t.Fatalf("Unable to add k8sv1alpha1 scheme: (%v)", err)
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
 	}
 	name := "my-service"
 	namespace := "my-nginx-ingress"
 	extraLabels := map[string]string{"app": "my-nginx-ingress"}
 	extraAnnotations := map[string]string{"app": "my-nginx-ingress"}
 
-	instance := &k8sv1alpha1.NginxIngressController{
+	instance := &k8sv1beta1.NginxIngressController{
 		ObjectMeta: v1.ObjectMeta{
 			Name:        name,
 			Namespace:   namespace,
 			Labels:      extraLabels,
 			Annotations: extraAnnotations,
 		},
-		Spec: k8sv1alpha1.NginxIngressControllerSpec{
+		Spec: k8sv1beta1.NginxIngressControllerSpec{
 			ServiceType: string(corev1.ServiceTypeLoadBalancer),
-			Service: &k8sv1alpha1.Service{
+			Service: &k8sv1beta1.Service{
 				ExtraLabels:      extraLabels,
 				ExtraAnnotations: extraAnnotations,
 			},
@@ -46,7 +46,7 @@ func TestServiceForNginxIngressController(t *testing.T) {
 			Annotations: extraAnnotations,
 			OwnerReferences: []v1.OwnerReference{
 				{
-					APIVersion:         "k8s.nginx.org/v1alpha1",
+					APIVersion:         "k8s.nginx.org/v1beta1",
 					Name:               instance.Name,
 					Kind:               "NginxIngressController",
 					Controller:         boolPointer(true),
@@ -1,14 +1,14 @@
 package controllers
 
 import (
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func serviceAccountForNginxIngressController(instance *k8sv1alpha1.NginxIngressController, scheme *runtime.Scheme) (*corev1.ServiceAccount, error) {
+func serviceAccountForNginxIngressController(instance *k8sv1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.ServiceAccount, error) {
 	svca := &corev1.ServiceAccount{
 		ObjectMeta: v1.ObjectMeta{
 			Name:      instance.Name,
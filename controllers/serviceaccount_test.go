@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -14,12 +14,12 @@ func TestServiceAccountForNginxIngressController(t *testing.T) {
 	boolPointer := func(b bool) *bool { return &b }
 	s := scheme.Scheme
 
-	if err := k8sv1alpha1.AddToScheme(s); err != nil {
-		t.Fatalf("Unable to add k8sv1alpha1 scheme: (%v)", err)
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
 	}
 	namespace := "my-nginx-ingress"
 	name := "my-sa"
-	instance := &k8sv1alpha1.NginxIngressController{
+	instance := &k8sv1beta1.NginxIngressController{
 		ObjectMeta: v1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -31,7 +31,7 @@ func TestServiceAccountForNginxIngressController(t *testing.T) {
 			Namespace: namespace,
 			OwnerReferences: []v1.OwnerReference{
 				{
-					APIVersion:         "k8s.nginx.org/v1alpha1",
+					APIVersion:         "k8s.nginx.org/v1beta1",
 					Name:               instance.Name,
 					Kind:               "NginxIngressController",
 					Controller:         boolPointer(true),
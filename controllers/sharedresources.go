@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=list;watch
+
+// contentHashAnnotation is the hash of the content the Operator last applied to a shared resource. It detects the changes of the
+// content between versions of the Operator, including removed fields, while the comparison with the live object detects the
+// changes made out of band.
+const contentHashAnnotation = "k8s.nginx.org/content-hash"
+
+// sharedResourcesPollInterval is the time a NginxIngressController waits for the shared resources to be reconciled.
+const sharedResourcesPollInterval = 5 * time.Second
+
+// sharedResourcesRequest is the only request of the SharedResourcesReconciler. All its watches are mapped to it.
+var sharedResourcesRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterRoleName}}
+
+// crdsStatus is the outcome of the last reconciliation of the KIC CRDs.
+type crdsStatus struct {
+	// reconciled is false until the CRDs are reconciled for the first time.
+	reconciled bool
+	err        error
+	installed  map[string]bool
+	// refused are the names of the CRDs whose update was refused because of breaking changes.
+	refused []string
+	// refusedChanges are the breaking changes of the refused updates by CRD name, so a refusal is only recorded once.
+	refusedChanges map[string]string
+}
+
+// missingCRDs returns the names of the CRDs required by the NginxIngressController that are not installed.
+func (s crdsStatus) missingCRDs(instance *k8sv1beta1.NginxIngressController) []string {
+	var missing []string
+	for _, name := range crdNamesForNginxIngressController(instance) {
+		if !s.installed[name] && !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// SharedResourcesReconciler reconciles the cluster-scoped resources shared by all the Ingress Controllers: the KIC CRDs. It also
+// removes the ClusterRole and the ClusterRoleBinding shared by earlier versions of the Operator once they are no longer used.
+// The resources are reconciled at startup, when they change and when the features enabled in the NginxIngressController
+// resources change, instead of on every reconcile of every NginxIngressController.
+type SharedResourcesReconciler struct {
+	client.Client
+	CRDClient apixv1client.CustomResourceDefinitionInterface
+	Recorder  record.EventRecorder
+	// CRDsDir is the directory with the manifests of the KIC CRDs. The manifests embedded in the Operator are used when it is empty.
+	CRDsDir string
+	// PruneCRDs enables the removal of the KIC CRDs installed by the Operator that no NginxIngressController needs.
+	PruneCRDs bool
+
+	mu     sync.RWMutex
+	status crdsStatus
+}
+
+// crdsStatus returns the outcome of the last reconciliation of the KIC CRDs.
+func (r *SharedResourcesReconciler) crdsStatus() crdsStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+func (r *SharedResourcesReconciler) setCRDsStatus(status crdsStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// Reconcile applies the shared resources.
+func (r *SharedResourcesReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	if err := r.deleteLegacyRBAC(ctx, log); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	installed, updates, err := r.reconcileKICCustomResourceDefinitions(ctx, log)
+	if err != nil {
+		err = fmt.Errorf("error creating KIC CRDs: %w", err)
+		crdInstallFailures.Inc()
+		r.setCRDsStatus(crdsStatus{reconciled: true, err: err})
+		return ctrl.Result{}, err
+	}
+
+	var refused []string
+	refusedChanges := map[string]string{}
+	for _, u := range updates {
+		if !u.allowed {
+			refused = append(refused, u.name)
+			refusedChanges[u.name] = strings.Join(u.changes, "; ")
+		}
+	}
+	r.recordCRDUpdates(updates, r.crdsStatus().refusedChanges)
+	r.setCRDsStatus(crdsStatus{reconciled: true, installed: installed, refused: refused, refusedChanges: refusedChanges})
+
+	return ctrl.Result{}, nil
+}
+
+// contentHash returns the hash of the content of a shared resource.
+func contentHash(content interface{}) (string, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// isContentUpToDate returns whether the shared resource was last applied with the content of the hash and was not changed since.
+func isContentUpToDate(obj metav1.Object, hash string, unchanged bool) bool {
+	return obj.GetAnnotations()[contentHashAnnotation] == hash && unchanged
+}
+
+func setContentHash(obj metav1.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[contentHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
+
+// deleteLegacyRBAC deletes the ClusterRoleBinding shared by the Ingress Controllers in earlier versions of the Operator once every
+// NginxIngressController has removed its ServiceAccount from the subjects, and the shared ClusterRole once no ClusterRoleBinding
+// refers to it.
+func (r *SharedResourcesReconciler) deleteLegacyRBAC(ctx context.Context, log logr.Logger) error {
+	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+
+	err := r.Get(ctx, types.NamespacedName{Name: clusterRoleName}, crb)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting ClusterRoleBinding: %w", err)
+	}
+	if err == nil {
+		if len(crb.Subjects) > 0 {
+			return nil
+		}
+		log.Info("legacy ClusterRoleBinding has no subjects, deleting.", "ClusterRoleBinding.Name", clusterRoleName)
+		if err := r.Delete(ctx, crb); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting ClusterRoleBinding: %w", err)
+		}
+	}
+
+	bindings := &rbacv1.ClusterRoleBindingList{}
+	if err := r.List(ctx, bindings); err != nil {
+		return fmt.Errorf("error listing ClusterRoleBindings: %w", err)
+	}
+	for _, b := range bindings.Items {
+		if b.Name != clusterRoleName && b.RoleRef.Name == clusterRoleName {
+			return nil
+		}
+	}
+
+	cr := &rbacv1.ClusterRole{}
+	err = r.Get(ctx, types.NamespacedName{Name: clusterRoleName}, cr)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error getting ClusterRole: %w", err)
+	}
+	log.Info("legacy ClusterRole is no longer used, deleting.", "ClusterRole.Name", clusterRoleName)
+	if err := r.Delete(ctx, cr); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting ClusterRole: %w", err)
+	}
+	return nil
+}
+
+// reconcileSharedResources checks that the KIC CRDs required by the NginxIngressController are installed and reports the
+// outcome in the CRDsInstalled and CRDsUpToDate conditions. It returns false when the NginxIngressController must wait for the
+// CRDs to be installed.
+func (r *NginxIngressControllerReconciler) reconcileSharedResources(ctx context.Context, instance *k8sv1beta1.NginxIngressController) (bool, error) {
+	status := r.SharedResources.crdsStatus()
+	if status.err != nil {
+		return false, r.reportFailure(ctx, instance, k8sv1beta1.ConditionCRDsInstalled, reasonCRDsInstallFailed, status.err)
+	}
+
+	missing := status.missingCRDs(instance)
+	if !status.reconciled || len(missing) > 0 {
+		sort.Strings(missing)
+		setCondition(instance, k8sv1beta1.ConditionCRDsInstalled, metav1.ConditionFalse, reasonCRDsPending,
+			fmt.Sprintf("Waiting for the CRDs to be installed: %v", strings.Join(missing, ", ")))
+		return false, nil
+	}
+
+	setCondition(instance, k8sv1beta1.ConditionCRDsInstalled, metav1.ConditionTrue, reasonCRDsInstalled, "")
+	setCRDsUpToDateCondition(instance, status.refused)
+	return true, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The shared resources are reconciled at startup and when the
+// legacy ClusterRoles, the ClusterRoleBindings, the KIC CRDs or the NginxIngressController resources change.
+func (r *SharedResourcesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	toSharedResources := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{sharedResourcesRequest}
+	})
+
+	kicCRDNames := map[string]bool{}
+	crds, err := kicCRDs(crdsFS(r.CRDsDir))
+	if err != nil {
+		return err
+	}
+	for _, crd := range crds {
+		kicCRDNames[crd.Name] = true
+	}
+
+	startup := make(chan event.GenericEvent, 1)
+	startup <- event.GenericEvent{Object: &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName}}}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sharedresources").
+		For(&rbacv1.ClusterRole{}, builder.WithPredicates(hasName(map[string]bool{clusterRoleName: true}))).
+		// The legacy ClusterRole is deleted once the ClusterRoleBindings no longer refer to it.
+		Watches(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}}, toSharedResources,
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == clusterRoleName || len(requestsForOwnerLabels(obj)) > 0
+			}))).
+		// Only the metadata of the CRDs is cached. The CRDs are read with the CRD client when they are reconciled.
+		Watches(&source.Kind{Type: &apixv1.CustomResourceDefinition{}}, toSharedResources,
+			builder.OnlyMetadata, builder.WithPredicates(hasName(kicCRDNames))).
+		Watches(&source.Kind{Type: &k8sv1beta1.NginxIngressController{}}, toSharedResources,
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, deletionPredicate()))).
+		Watches(&source.Channel{Source: startup}, toSharedResources).
+		Complete(r)
+}
+
+// hasName filters the events of the objects with the given names.
+func hasName(names map[string]bool) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return names[obj.GetName()]
+	})
+}
+
+// deletionPredicate filters the events of the objects marked to be deleted.
+func deletionPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetDeletionTimestamp() == nil && e.ObjectNew.GetDeletionTimestamp() != nil
+		},
+	}
+}
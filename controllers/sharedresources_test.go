@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsContentUpToDate(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		unchanged   bool
+		expected    bool
+		msg         string
+	}{
+		{
+			annotations: map[string]string{contentHashAnnotation: "hash"},
+			unchanged:   true,
+			expected:    true,
+			msg:         "same hash and unchanged",
+		},
+		{
+			annotations: map[string]string{contentHashAnnotation: "hash"},
+			unchanged:   false,
+			expected:    false,
+			msg:         "same hash and changed out of band",
+		},
+		{
+			annotations: map[string]string{contentHashAnnotation: "previous"},
+			unchanged:   true,
+			expected:    false,
+			msg:         "content changed in the Operator",
+		},
+		{
+			annotations: nil,
+			unchanged:   true,
+			expected:    false,
+			msg:         "no hash",
+		},
+	}
+
+	for _, test := range tests {
+		obj := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+		if result := isContentUpToDate(obj, "hash", test.unchanged); result != test.expected {
+			t.Errorf("isContentUpToDate() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestReconcileSharedResources(t *testing.T) {
+	tests := []struct {
+		status          crdsStatus
+		appProtect      bool
+		expectedReady   bool
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+		msg             string
+	}{
+		{
+			status:          crdsStatus{},
+			expectedReady:   false,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  reasonCRDsPending,
+			expectedMessage: "Waiting for the CRDs to be installed: policies.k8s.nginx.org, transportservers.k8s.nginx.org, virtualserverroutes.k8s.nginx.org, virtualservers.k8s.nginx.org",
+			msg:             "shared resources not reconciled yet",
+		},
+		{
+			status: crdsStatus{reconciled: true, installed: map[string]bool{
+				crdVirtualServers: true, crdVirtualServerRoutes: true, crdTransportServers: true, crdPolicies: true,
+			}},
+			appProtect:      true,
+			expectedReady:   false,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  reasonCRDsPending,
+			expectedMessage: "Waiting for the CRDs to be installed: aplogconfs.appprotect.f5.com, appolicies.appprotect.f5.com, apusersigs.appprotect.f5.com",
+			msg:             "CRDs of a new feature not installed yet",
+		},
+		{
+			status: crdsStatus{reconciled: true, installed: map[string]bool{
+				crdVirtualServers: true, crdVirtualServerRoutes: true, crdTransportServers: true, crdPolicies: true,
+			}},
+			expectedReady:  true,
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: reasonCRDsInstalled,
+			msg:            "CRDs installed",
+		},
+	}
+
+	for _, test := range tests {
+		r := &NginxIngressControllerReconciler{SharedResources: &SharedResourcesReconciler{status: test.status}, Recorder: record.NewFakeRecorder(10)}
+		instance := &k8sv1beta1.NginxIngressController{}
+		if test.appProtect {
+			instance.Spec.AppProtect = &k8sv1beta1.AppProtect{Enable: true}
+		}
+
+		ready, err := r.reconcileSharedResources(context.Background(), instance)
+		if err != nil {
+			t.Fatalf("reconcileSharedResources() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		if ready != test.expectedReady {
+			t.Errorf("reconcileSharedResources() returned %v but expected %v for the case of %v", ready, test.expectedReady, test.msg)
+		}
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, k8sv1beta1.ConditionCRDsInstalled)
+		if condition == nil || condition.Status != test.expectedStatus || condition.Reason != test.expectedReason || condition.Message != test.expectedMessage {
+			t.Errorf("reconcileSharedResources() set the condition %+v but expected %v %v %q for the case of %v",
+				condition, test.expectedStatus, test.expectedReason, test.expectedMessage, test.msg)
+		}
+	}
+}
+
+func TestDeleteLegacyRBAC(t *testing.T) {
+	instance := &k8sv1beta1.NginxIngressController{ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "default"}}
+
+	tests := []struct {
+		subjects        []rbacv1.Subject
+		bindings        []client.Object
+		expectedBinding bool
+		expectedRoles   []string
+		msg             string
+	}{
+		{
+			subjects:        []rbacv1.Subject{subjectForServiceAccount("default", "my-nginx-ingress")},
+			expectedBinding: true,
+			expectedRoles:   []string{clusterRoleName},
+			msg:             "legacy ClusterRoleBinding with subjects",
+		},
+		{
+			subjects:        nil,
+			bindings:        []client.Object{clusterRoleBindingForInstance(instance, clusterRoleName)},
+			expectedBinding: false,
+			expectedRoles:   []string{clusterRoleName},
+			msg:             "legacy ClusterRole still bound to a NginxIngressController",
+		},
+		{
+			subjects:        nil,
+			bindings:        []client.Object{clusterRoleBindingForInstance(instance, rbacName(instance))},
+			expectedBinding: false,
+			expectedRoles:   nil,
+			msg:             "legacy ClusterRoleBinding without subjects",
+		},
+	}
+
+	for _, test := range tests {
+		crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+		crb.Subjects = test.subjects
+		objects := append([]client.Object{
+			crb,
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName}},
+		}, test.bindings...)
+		c := fake.NewClientBuilder().WithScheme(newCATestScheme(t)).WithObjects(objects...).Build()
+		r := &SharedResourcesReconciler{Client: c}
+
+		if err := r.deleteLegacyRBAC(context.Background(), logr.Discard()); err != nil {
+			t.Fatalf("deleteLegacyRBAC() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+		err := c.Get(context.Background(), client.ObjectKeyFromObject(crb), &rbacv1.ClusterRoleBinding{})
+		if exists := err == nil; exists != test.expectedBinding {
+			t.Errorf("deleteLegacyRBAC() left the ClusterRoleBinding %v but expected %v for the case of %v", exists, test.expectedBinding, test.msg)
+		}
+
+		roles := &rbacv1.ClusterRoleList{}
+		if err := c.List(context.Background(), roles); err != nil {
+			t.Fatalf("failed to list the ClusterRoles: %v", err)
+		}
+		var names []string
+		for _, role := range roles.Items {
+			names = append(names, role.Name)
+		}
+		if diff := cmp.Diff(test.expectedRoles, names); diff != "" {
+			t.Errorf("deleteLegacyRBAC() ClusterRoles mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+
+	r := &SharedResourcesReconciler{Client: fake.NewClientBuilder().WithScheme(newCATestScheme(t)).Build()}
+	if err := r.deleteLegacyRBAC(context.Background(), logr.Discard()); err != nil {
+		t.Errorf("deleteLegacyRBAC() returned unexpected error %v when the legacy resources don't exist", err)
+	}
+}
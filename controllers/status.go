@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Reasons of the conditions reported in the status of a NginxIngressController.
+const (
+	reasonPrerequisitesCreated  = "PrerequisitesCreated"
+	reasonCommonResourcesFailed = "CommonResourcesFailed"
+	reasonPrerequisitesFailed   = "PrerequisitesFailed"
+	reasonSCCUpdateFailed       = "SCCUpdateFailed"
+	reasonCRDsInstalled         = "CRDsInstalled"
+	reasonCRDsInstallFailed     = "CRDsInstallFailed"
+	reasonCRDsPending           = "CRDsPending"
+	reasonWorkloadFailed        = "WorkloadFailed"
+	reasonServiceFailed         = "ServiceFailed"
+	reasonConfigMapFailed       = "ConfigMapFailed"
+	reasonRollingOut            = "RollingOut"
+	reasonRolloutComplete       = "RolloutComplete"
+	reasonRolloutFailed         = "RolloutFailed"
+	reasonPodsUnavailable       = "PodsUnavailable"
+	reasonPodsAvailable         = "PodsAvailable"
+	reasonAsExpected            = "AsExpected"
+)
+
+// deploymentProgressDeadlineExceeded is the reason of the Progressing condition of a Deployment that failed to roll out.
+const deploymentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// workloadStatus is the part of the status of a Deployment or DaemonSet used to compute the status of a NginxIngressController.
+type workloadStatus struct {
+	image     string
+	desired   int32
+	updated   int32
+	available int32
+	// observed is true when the workload controller has processed the latest spec of the workload.
+	observed bool
+	// failure is set when the workload controller reports that the rollout has failed.
+	failure string
+}
+
+func workloadStatusForDeployment(dep *appsv1.Deployment) workloadStatus {
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+
+	ws := workloadStatus{
+		image:     workloadImage(dep.Spec.Template.Spec),
+		desired:   desired,
+		updated:   dep.Status.UpdatedReplicas,
+		available: dep.Status.AvailableReplicas,
+		observed:  dep.Status.ObservedGeneration >= dep.Generation,
+	}
+
+	for _, c := range dep.Status.Conditions {
+		if c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue {
+			ws.failure = c.Message
+		}
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == deploymentProgressDeadlineExceeded {
+			ws.failure = c.Message
+		}
+	}
+
+	return ws
+}
+
+func workloadStatusForDaemonSet(ds *appsv1.DaemonSet) workloadStatus {
+	return workloadStatus{
+		image:     workloadImage(ds.Spec.Template.Spec),
+		desired:   ds.Status.DesiredNumberScheduled,
+		updated:   ds.Status.UpdatedNumberScheduled,
+		available: ds.Status.NumberAvailable,
+		observed:  ds.Status.ObservedGeneration >= ds.Generation,
+	}
+}
+
+func workloadImage(spec corev1.PodSpec) string {
+	// There is only 1 container in our template
+	if len(spec.Containers) == 0 {
+		return ""
+	}
+	return spec.Containers[0].Image
+}
+
+// setCondition adds or updates a condition in the status of the NginxIngressController.
+func setCondition(instance *k8sv1beta1.NginxIngressController, conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.Generation,
+	})
+}
+
+// setWorkloadStatus updates the replica counts, image and the Ready, Progressing and Degraded conditions
+// of the NginxIngressController based on the status of its Deployment or DaemonSet.
+func setWorkloadStatus(instance *k8sv1beta1.NginxIngressController, ws workloadStatus) {
+	instance.Status.Image = ws.image
+	instance.Status.DesiredReplicas = ws.desired
+	instance.Status.AvailableReplicas = ws.available
+
+	available := fmt.Sprintf("%v of %v pods available", ws.available, ws.desired)
+
+	switch {
+	case ws.failure != "":
+		setCondition(instance, k8sv1beta1.ConditionProgressing, metav1.ConditionFalse, reasonRolloutFailed, ws.failure)
+		setCondition(instance, k8sv1beta1.ConditionDegraded, metav1.ConditionTrue, reasonRolloutFailed, ws.failure)
+		setCondition(instance, k8sv1beta1.ConditionReady, metav1.ConditionFalse, reasonRolloutFailed, available)
+	case !ws.observed || ws.updated < ws.desired:
+		setCondition(instance, k8sv1beta1.ConditionProgressing, metav1.ConditionTrue, reasonRollingOut,
+			fmt.Sprintf("%v of %v pods updated", ws.updated, ws.desired))
+		setCondition(instance, k8sv1beta1.ConditionDegraded, metav1.ConditionFalse, reasonAsExpected, "")
+		setCondition(instance, k8sv1beta1.ConditionReady, metav1.ConditionFalse, reasonRollingOut, available)
+	case ws.available < ws.desired:
+		setCondition(instance, k8sv1beta1.ConditionProgressing, metav1.ConditionFalse, reasonRolloutComplete, "")
+		setCondition(instance, k8sv1beta1.ConditionDegraded, metav1.ConditionTrue, reasonPodsUnavailable, available)
+		setCondition(instance, k8sv1beta1.ConditionReady, metav1.ConditionFalse, reasonPodsUnavailable, available)
+	default:
+		setCondition(instance, k8sv1beta1.ConditionProgressing, metav1.ConditionFalse, reasonRolloutComplete, "")
+		setCondition(instance, k8sv1beta1.ConditionDegraded, metav1.ConditionFalse, reasonAsExpected, "")
+		setCondition(instance, k8sv1beta1.ConditionReady, metav1.ConditionTrue, reasonPodsAvailable, available)
+	}
+}
+
+// reportFailure sets the given condition to False, marks the NginxIngressController as Degraded and not Ready,
+// records a Warning event and updates its status. It returns the original error so it can be returned by Reconcile.
+func (r *NginxIngressControllerReconciler) reportFailure(ctx context.Context, instance *k8sv1beta1.NginxIngressController, conditionType string, reason string, err error) error {
+	if conditionType != k8sv1beta1.ConditionDegraded {
+		setCondition(instance, conditionType, metav1.ConditionFalse, reason, err.Error())
+	}
+	setCondition(instance, k8sv1beta1.ConditionDegraded, metav1.ConditionTrue, reason, err.Error())
+	setCondition(instance, k8sv1beta1.ConditionReady, metav1.ConditionFalse, reason, err.Error())
+	r.Recorder.Event(instance, corev1.EventTypeWarning, reason, err.Error())
+
+	if statusErr := r.Status().Update(ctx, instance); statusErr != nil {
+		ctrllog.FromContext(ctx).Error(statusErr, "Failed to update NginxIngressController status")
+	}
+
+	return err
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadStatusForDeployment(t *testing.T) {
+	replicas := int32(3)
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "my-nginx-ingress-controller",
+				Image: "nginx-ingress:edge",
+			},
+		},
+	}
+
+	tests := []struct {
+		deployment *appsv1.Deployment
+		expected   workloadStatus
+		msg        string
+	}{
+		{
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Template: corev1.PodTemplateSpec{Spec: podSpec},
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  2,
+				},
+			},
+			expected: workloadStatus{
+				image:     "nginx-ingress:edge",
+				desired:   3,
+				updated:   3,
+				available: 2,
+				observed:  true,
+			},
+			msg: "rolled out deployment",
+		},
+		{
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{Spec: podSpec},
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+				},
+			},
+			expected: workloadStatus{
+				image:   "nginx-ingress:edge",
+				desired: 1,
+			},
+			msg: "default replicas and new generation not observed",
+		},
+		{
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Template: corev1.PodTemplateSpec{Spec: podSpec},
+				},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1,
+					Conditions: []appsv1.DeploymentCondition{
+						{
+							Type:    appsv1.DeploymentProgressing,
+							Status:  corev1.ConditionFalse,
+							Reason:  "ProgressDeadlineExceeded",
+							Message: "ReplicaSet has timed out progressing.",
+						},
+					},
+				},
+			},
+			expected: workloadStatus{
+				image:    "nginx-ingress:edge",
+				desired:  3,
+				observed: true,
+				failure:  "ReplicaSet has timed out progressing.",
+			},
+			msg: "progress deadline exceeded",
+		},
+	}
+
+	for _, test := range tests {
+		result := workloadStatusForDeployment(test.deployment)
+		if diff := cmp.Diff(test.expected, result, cmp.AllowUnexported(workloadStatus{})); diff != "" {
+			t.Errorf("workloadStatusForDeployment() mismatch for the case of %v (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestWorkloadStatusForDaemonSet(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "my-nginx-ingress-controller",
+							Image: "nginx-ingress:edge",
+						},
+					},
+				},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     1,
+			DesiredNumberScheduled: 4,
+			UpdatedNumberScheduled: 4,
+			NumberAvailable:        3,
+		},
+	}
+	expected := workloadStatus{
+		image:     "nginx-ingress:edge",
+		desired:   4,
+		updated:   4,
+		available: 3,
+		observed:  true,
+	}
+
+	result := workloadStatusForDaemonSet(ds)
+	if diff := cmp.Diff(expected, result, cmp.AllowUnexported(workloadStatus{})); diff != "" {
+		t.Errorf("workloadStatusForDaemonSet() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetWorkloadStatus(t *testing.T) {
+	tests := []struct {
+		ws          workloadStatus
+		ready       metav1.ConditionStatus
+		progressing metav1.ConditionStatus
+		degraded    metav1.ConditionStatus
+		msg         string
+	}{
+		{
+			ws:          workloadStatus{desired: 2, updated: 2, available: 2, observed: true},
+			ready:       metav1.ConditionTrue,
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionFalse,
+			msg:         "all pods available",
+		},
+		{
+			ws:          workloadStatus{desired: 2, updated: 1, available: 2, observed: true},
+			ready:       metav1.ConditionFalse,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionFalse,
+			msg:         "rolling update in progress",
+		},
+		{
+			ws:          workloadStatus{desired: 2, updated: 2, available: 2, observed: false},
+			ready:       metav1.ConditionFalse,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionFalse,
+			msg:         "new generation not observed",
+		},
+		{
+			ws:          workloadStatus{desired: 2, updated: 2, available: 1, observed: true},
+			ready:       metav1.ConditionFalse,
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionTrue,
+			msg:         "pods unavailable after rollout",
+		},
+		{
+			ws:          workloadStatus{desired: 2, updated: 1, available: 1, observed: true, failure: "timed out"},
+			ready:       metav1.ConditionFalse,
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionTrue,
+			msg:         "rollout failed",
+		},
+	}
+
+	for _, test := range tests {
+		instance := &k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Generation: 5},
+		}
+
+		setWorkloadStatus(instance, test.ws)
+
+		expected := map[string]metav1.ConditionStatus{
+			k8sv1beta1.ConditionReady:       test.ready,
+			k8sv1beta1.ConditionProgressing: test.progressing,
+			k8sv1beta1.ConditionDegraded:    test.degraded,
+		}
+		for conditionType, status := range expected {
+			c := meta.FindStatusCondition(instance.Status.Conditions, conditionType)
+			if c == nil {
+				t.Errorf("setWorkloadStatus() did not set the %v condition for the case of %v", conditionType, test.msg)
+				continue
+			}
+			if c.Status != status {
+				t.Errorf("setWorkloadStatus() set the %v condition to %v but expected %v for the case of %v", conditionType, c.Status, status, test.msg)
+			}
+			if c.ObservedGeneration != 5 {
+				t.Errorf("setWorkloadStatus() set observedGeneration %v on the %v condition but expected 5 for the case of %v", c.ObservedGeneration, conditionType, test.msg)
+			}
+		}
+
+		if instance.Status.DesiredReplicas != test.ws.desired || instance.Status.AvailableReplicas != test.ws.available {
+			t.Errorf("setWorkloadStatus() set %v/%v replicas but expected %v/%v for the case of %v",
+				instance.Status.AvailableReplicas, instance.Status.DesiredReplicas, test.ws.available, test.ws.desired, test.msg)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const nginxIngressControllerCRDName = "nginxingresscontrollers.k8s.nginx.org"
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update
+
+// StorageVersionMigrator rewrites the stored NginxIngressController resources in the storage version (v1beta1)
+// and removes the previous versions from the storedVersions of the CRD, so the previous versions can later
+// be removed from the CRD.
+type StorageVersionMigrator struct {
+	Client    client.Client
+	Reader    client.Reader
+	CRDClient apixv1client.CustomResourceDefinitionInterface
+	Log       logr.Logger
+}
+
+// Start runs the migration once. A failed migration is logged and retried on the next start of the Operator.
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	if err := m.migrate(ctx); err != nil {
+		m.Log.Error(err, "failed to migrate the storage version of NginxIngressController resources")
+	}
+	return nil
+}
+
+// NeedLeaderElection makes sure only the leader runs the migration.
+func (m *StorageVersionMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+func (m *StorageVersionMigrator) migrate(ctx context.Context) error {
+	storageVersion := k8sv1beta1.GroupVersion.Version
+
+	crd, err := m.CRDClient.Get(ctx, nginxIngressControllerCRDName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting CRD %v: %w", nginxIngressControllerCRDName, err)
+	}
+
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		m.Log.V(1).Info("NginxIngressController resources are already stored in the storage version", "version", storageVersion)
+		return nil
+	}
+
+	list := &k8sv1beta1.NginxIngressControllerList{}
+	if err := m.Reader.List(ctx, list); err != nil {
+		return fmt.Errorf("error listing NginxIngressController resources: %w", err)
+	}
+
+	// An empty patch makes the API server write the resource again in the storage version.
+	for i := range list.Items {
+		instance := &list.Items[i]
+		err := m.Client.Patch(ctx, instance, client.RawPatch(types.MergePatchType, []byte("{}")))
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("error migrating NginxIngressController %v/%v: %w", instance.Namespace, instance.Name, err)
+		}
+	}
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	if _, err := m.CRDClient.UpdateStatus(ctx, crd, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating the stored versions of CRD %v: %w", nginxIngressControllerCRDName, err)
+	}
+
+	m.Log.Info("Migrated NginxIngressController resources to the storage version", "version", storageVersion, "count", len(list.Items))
+	return nil
+}
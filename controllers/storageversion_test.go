@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStorageVersionMigratorMigrate(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := k8sv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add k8sv1beta1 scheme: (%v)", err)
+	}
+
+	tests := []struct {
+		storedVersions []string
+		expected       []string
+		msg            string
+	}{
+		{
+			storedVersions: []string{"v1alpha1", "v1beta1"},
+			expected:       []string{"v1beta1"},
+			msg:            "previous version stored",
+		},
+		{
+			storedVersions: []string{"v1beta1"},
+			expected:       []string{"v1beta1"},
+			msg:            "already migrated",
+		},
+	}
+
+	for _, test := range tests {
+		crd := &apixv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: nginxIngressControllerCRDName},
+			Status: apixv1.CustomResourceDefinitionStatus{
+				StoredVersions: test.storedVersions,
+			},
+		}
+		instance := &k8sv1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-nginx-ingress", Namespace: "my-nginx-ingress"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(instance).Build()
+		crdClient := apixfake.NewSimpleClientset(crd).ApiextensionsV1().CustomResourceDefinitions()
+
+		m := &StorageVersionMigrator{
+			Client:    c,
+			Reader:    c,
+			CRDClient: crdClient,
+			Log:       logr.Discard(),
+		}
+		if err := m.migrate(context.Background()); err != nil {
+			t.Fatalf("migrate() returned unexpected error %v for the case of %v", err, test.msg)
+		}
+
+		result, err := crdClient.Get(context.Background(), nginxIngressControllerCRDName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get CRD: %v", err)
+		}
+		if len(result.Status.StoredVersions) != len(test.expected) || result.Status.StoredVersions[0] != test.expected[0] {
+			t.Errorf("migrate() set storedVersions %v but expected %v for the case of %v", result.Status.StoredVersions, test.expected, test.msg)
+		}
+	}
+}
+
+func TestStorageVersionMigratorMigrateMissingCRD(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	m := &StorageVersionMigrator{
+		Client:    c,
+		Reader:    c,
+		CRDClient: apixfake.NewSimpleClientset().ApiextensionsV1().CustomResourceDefinitions(),
+		Log:       logr.Discard(),
+	}
+	if err := m.migrate(context.Background()); err == nil {
+		t.Errorf("migrate() returned no error for a missing CRD")
+	}
+}
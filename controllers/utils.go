@@ -2,12 +2,10 @@ package controllers
 
 import (
 	"fmt"
-	"reflect"
 	"strings"
 
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	secv1 "github.com/openshift/api/security/v1"
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/discovery"
@@ -21,14 +19,14 @@ const apiVersionUnsupportedError = "server does not support API version"
 var RunningK8sVersion *version.Version
 
 // generatePodArgs generate a list of arguments for the Ingress Controller pods based on the CRD.
-func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
+func generatePodArgs(instance *k8sv1beta1.NginxIngressController) []string {
 	var args []string
 
 	args = append(args, fmt.Sprintf("-nginx-configmaps=%v/%v", instance.Namespace, instance.Name))
 
-	defaultSecretName := instance.Spec.DefaultSecret
-	if instance.Spec.DefaultSecret == "" {
-		defaultSecretName = fmt.Sprintf("%v/%v", instance.Namespace, instance.Name)
+	defaultSecretName := fmt.Sprintf("%v/%v", instance.Namespace, instance.Name)
+	if instance.Spec.DefaultSecret != nil {
+		defaultSecretName = objectReferenceArg(instance.Spec.DefaultSecret, instance.Namespace)
 	}
 	args = append(args, fmt.Sprintf("-default-server-tls-secret=%v", defaultSecretName))
 
@@ -70,6 +68,12 @@ func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
 		}
 	}
 
+	if !isReadyStatusEnabled(instance) {
+		args = append(args, "-ready-status=false")
+	} else if instance.Spec.ReadyStatus != nil && instance.Spec.ReadyStatus.Port != nil {
+		args = append(args, fmt.Sprintf("-ready-status-port=%v", *instance.Spec.ReadyStatus.Port))
+	}
+
 	if instance.Spec.NginxDebug {
 		args = append(args, "-nginx-debug")
 	}
@@ -85,8 +89,8 @@ func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
 			args = append(args, fmt.Sprintf("-nginx-status-port=%v", *instance.Spec.NginxStatus.Port))
 		}
 
-		if instance.Spec.NginxStatus.AllowCidrs != "" {
-			args = append(args, fmt.Sprintf("-nginx-status-allow-cidrs=%v", instance.Spec.NginxStatus.AllowCidrs))
+		if len(instance.Spec.NginxStatus.AllowCidrs) > 0 {
+			args = append(args, fmt.Sprintf("-nginx-status-allow-cidrs=%v", strings.Join(instance.Spec.NginxStatus.AllowCidrs, ",")))
 		}
 	}
 
@@ -108,8 +112,10 @@ func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
 		args = append(args, "-enable-leader-election=false")
 	}
 
-	if instance.Spec.WildcardTLS != "" {
-		args = append(args, fmt.Sprintf("-wildcard-tls-secret=%v", instance.Spec.WildcardTLS))
+	if instance.Spec.WildcardTLS != nil {
+		args = append(args, fmt.Sprintf("-wildcard-tls-secret=%v", objectReferenceArg(instance.Spec.WildcardTLS, instance.Namespace)))
+	} else if issuesWildcardCertificate(instance) || certManagerIssuesWildcard(instance) {
+		args = append(args, fmt.Sprintf("-wildcard-tls-secret=%v/%v", instance.Namespace, wildcardSecretName(instance)))
 	}
 
 	if instance.Spec.Prometheus != nil && instance.Spec.Prometheus.Enable {
@@ -123,8 +129,10 @@ func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
 			args = append(args, "-enable-latency-metrics")
 		}
 
-		if instance.Spec.Prometheus.Secret != "" {
-			args = append(args, fmt.Sprintf("-prometheus-tls-secret=%v", instance.Spec.Prometheus.Secret))
+		if instance.Spec.Prometheus.Secret != nil {
+			args = append(args, fmt.Sprintf("-prometheus-tls-secret=%v", objectReferenceArg(instance.Spec.Prometheus.Secret, instance.Namespace)))
+		} else if issuesPrometheusCertificate(instance) || certManagerIssuesPrometheus(instance) {
+			args = append(args, fmt.Sprintf("-prometheus-tls-secret=%v/%v", instance.Namespace, prometheusSecretName(instance)))
 		}
 	}
 
@@ -135,8 +143,8 @@ func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
 			args = append(args, "-enable-tls-passthrough")
 		}
 
-		if instance.Spec.GlobalConfiguration != "" {
-			args = append(args, fmt.Sprintf("-global-configuration=%v", instance.Spec.GlobalConfiguration))
+		if gc := globalConfigurationForNginxIngressController(instance); gc != nil {
+			args = append(args, fmt.Sprintf("-global-configuration=%v", objectReferenceArg(gc, instance.Namespace)))
 		}
 
 		if instance.Spec.EnableSnippets {
@@ -155,10 +163,13 @@ func generatePodArgs(instance *k8sv1alpha1.NginxIngressController) []string {
 	return args
 }
 
-// hasDifferentArguments returns whether the arguments of a container are different than the NginxIngressController spec.
-func hasDifferentArguments(container corev1.Container, instance *k8sv1alpha1.NginxIngressController) bool {
-	newArgs := generatePodArgs(instance)
-	return !reflect.DeepEqual(newArgs, container.Args)
+// objectReferenceArg formats a reference as namespace/name. A reference without a namespace refers to defaultNamespace.
+func objectReferenceArg(ref *k8sv1beta1.ObjectReference, defaultNamespace string) string {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return fmt.Sprintf("%v/%v", namespace, ref.Name)
 }
 
 func VerifySCCAPIExists() (bool, error) {
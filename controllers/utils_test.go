@@ -5,30 +5,30 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGeneratePodArgs(t *testing.T) {
-	var promPort, statusPort uint16
+	var promPort, statusPort, readyPort uint16
 	promPort = 9114
 	statusPort = 9090
+	readyPort = 9091
 	name := "my-nginx-ingress"
 	namespace := "my-nginx-ingress"
 	enable := true
 	disable := false
 	tests := []struct {
-		instance *k8sv1alpha1.NginxIngressController
+		instance *k8sv1beta1.NginxIngressController
 		expected []string
 	}{
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{},
+				Spec: k8sv1beta1.NginxIngressControllerSpec{},
 			},
 			expected: []string{
 				"-nginx-configmaps=my-nginx-ingress/my-nginx-ingress",
@@ -37,13 +37,13 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					DefaultSecret: "my-nginx-ingress/my-secret",
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					DefaultSecret: &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "my-secret"},
 				},
 			},
 			expected: []string{
@@ -53,12 +53,57 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					Prometheus: &k8sv1beta1.Prometheus{Enable: true},
+					OperatorCA: &k8sv1beta1.OperatorCA{
+						DefaultServer:  true,
+						WildcardDomain: "example.com",
+						Prometheus:     true,
+					},
+				},
+			},
+			expected: []string{
+				"-nginx-configmaps=my-nginx-ingress/my-nginx-ingress",
+				"-default-server-tls-secret=my-nginx-ingress/my-nginx-ingress",
+				"-leader-election-lock-name=my-nginx-ingress-lock",
+				"-wildcard-tls-secret=my-nginx-ingress/my-nginx-ingress-wildcard-tls",
+				"-enable-prometheus-metrics",
+				"-prometheus-tls-secret=my-nginx-ingress/my-nginx-ingress-prometheus-tls",
+			},
+		},
+		{
+			instance: &k8sv1beta1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					CertManager: &k8sv1beta1.CertManager{
+						IssuerRef:      k8sv1beta1.CertManagerIssuerReference{Name: "my-issuer"},
+						WildcardDomain: "example.com",
+						Prometheus:     true,
+					},
+				},
+			},
+			expected: []string{
+				"-nginx-configmaps=my-nginx-ingress/my-nginx-ingress",
+				"-default-server-tls-secret=my-nginx-ingress/my-nginx-ingress",
+				"-leader-election-lock-name=my-nginx-ingress-lock",
+				"-wildcard-tls-secret=my-nginx-ingress/my-nginx-ingress-wildcard-tls",
+			},
+		},
+		{
+			instance: &k8sv1beta1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
 					NginxPlus: true,
 				},
 			},
@@ -70,12 +115,12 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
 					EnableCRDs: &disable,
 				},
 			},
@@ -87,15 +132,15 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
 					NginxPlus:     true,
 					EnableCRDs:    &disable,
-					DefaultSecret: "my-nginx-ingress/my-secret",
+					DefaultSecret: &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "my-secret"},
 				},
 			},
 			expected: []string{
@@ -107,15 +152,15 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					DefaultSecret: "my-nginx-ingress/my-secret",
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					DefaultSecret: &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "my-secret"},
 					ServiceType:   "NodePort",
-					ReportIngressStatus: &k8sv1alpha1.ReportIngressStatus{
+					ReportIngressStatus: &k8sv1beta1.ReportIngressStatus{
 						Enable:      true,
 						IngressLink: "my-ingresslink",
 					},
@@ -130,15 +175,15 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					DefaultSecret: "my-nginx-ingress/my-secret",
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					DefaultSecret: &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "my-secret"},
 					ServiceType:   "LoadBalancer",
-					ReportIngressStatus: &k8sv1alpha1.ReportIngressStatus{
+					ReportIngressStatus: &k8sv1beta1.ReportIngressStatus{
 						Enable:      true,
 						IngressLink: "my-invalid-ingresslink",
 					},
@@ -153,17 +198,17 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
 					EnableCRDs:            &enable,
 					EnableSnippets:        true,
 					EnablePreviewPolicies: true,
 					EnableTLSPassthrough:  true,
-					GlobalConfiguration:   "my-nginx-ingress/globalconfiguration",
+					GlobalConfiguration:   &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "globalconfiguration"},
 				},
 			},
 			expected: []string{
@@ -177,12 +222,32 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					EnableCRDs: &enable,
+					IngressClassParameters: &k8sv1beta1.IngressClassParametersSpec{
+						GlobalConfiguration: &k8sv1beta1.ObjectReference{Name: "globalconfiguration"},
+					},
+				},
+			},
+			expected: []string{
+				"-nginx-configmaps=my-nginx-ingress/my-nginx-ingress",
+				"-default-server-tls-secret=my-nginx-ingress/my-nginx-ingress",
+				"-leader-election-lock-name=my-nginx-ingress-lock",
+				"-global-configuration=my-nginx-ingress/globalconfiguration",
+			},
+		},
+		{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
 					EnableLeaderElection: &disable,
 				},
 			},
@@ -193,46 +258,46 @@ func TestGeneratePodArgs(t *testing.T) {
 			},
 		},
 		{
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
 					NginxPlus:      true,
-					DefaultSecret:  "my-nginx-ingress/my-secret",
+					DefaultSecret:  &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "my-secret"},
 					IngressClass:   "ingressClass",
 					WatchNamespace: "default",
-					HealthStatus: &k8sv1alpha1.HealthStatus{
+					HealthStatus: &k8sv1beta1.HealthStatus{
 						Enable: true,
 						URI:    "/healthz",
 					},
 					NginxDebug: true,
 					LogLevel:   3,
-					NginxStatus: &k8sv1alpha1.NginxStatus{
+					NginxStatus: &k8sv1beta1.NginxStatus{
 						Enable:     true,
 						Port:       &statusPort,
-						AllowCidrs: "127.0.0.1",
+						AllowCidrs: []string{"127.0.0.1"},
 					},
-					ReportIngressStatus: &k8sv1alpha1.ReportIngressStatus{
+					ReportIngressStatus: &k8sv1beta1.ReportIngressStatus{
 						Enable:          true,
 						ExternalService: "external",
 						IngressLink:     "my-invalid-ingressLink",
 					},
 					EnableLeaderElection: &enable,
-					WildcardTLS:          "my-nginx-ingress/wildcard-secret",
-					Prometheus: &k8sv1alpha1.Prometheus{
+					WildcardTLS:          &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "wildcard-secret"},
+					Prometheus: &k8sv1beta1.Prometheus{
 						Enable: true,
 						Port:   &promPort,
-						Secret: "my-nginx-ingress/prometheus-secret",
+						Secret: &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "prometheus-secret"},
 					},
 					EnableLatencyMetrics: true,
-					GlobalConfiguration:  "my-nginx-ingress/globalconfiguration",
+					GlobalConfiguration:  &k8sv1beta1.ObjectReference{Namespace: "my-nginx-ingress", Name: "globalconfiguration"},
 					EnableTLSPassthrough: true,
-					AppProtect: &k8sv1alpha1.AppProtect{
+					AppProtect: &k8sv1beta1.AppProtect{
 						Enable: true,
 					},
-					AppProtectDos: &k8sv1alpha1.AppProtectDos{
+					AppProtectDos: &k8sv1beta1.AppProtectDos{
 						Enable:     true,
 						Debug:      true,
 						MaxDaemons: 12,
@@ -276,114 +341,51 @@ func TestGeneratePodArgs(t *testing.T) {
 				"-nginx-reload-timeout=5000",
 			},
 		},
-	}
-
-	for _, test := range tests {
-		result := generatePodArgs(test.instance)
-		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("generatePodArgs(%+v) mismatch (-want +got):\n%s", test.instance, diff)
-		}
-	}
-}
-
-func TestHasDifferentArguments(t *testing.T) {
-	name := "my-nginx-ingress"
-	namespace := "my-nginx-ingress"
-	tests := []struct {
-		container corev1.Container
-		instance  *k8sv1alpha1.NginxIngressController
-		expected  bool
-	}{
 		{
-			container: corev1.Container{
-				Args: []string{
-					fmt.Sprintf("-nginx-configmaps=%v/%v", namespace, name),
-					fmt.Sprintf("-default-server-tls-secret=%v/%v", namespace, name),
-					"-nginx-plus",
-					"-leader-election-lock-name=my-nginx-ingress-lock",
-				},
-			},
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					NginxPlus: true,
-				},
-			},
-			expected: false,
-		},
-		{
-			container: corev1.Container{
-				Args: []string{
-					fmt.Sprintf("-nginx-configmaps=%v/%v", namespace, name),
-					fmt.Sprintf("-default-server-tls-secret=%v/%v", namespace, name),
-					"-nginx-plus=false",
-					"-leader-election-lock-name=my-nginx-ingress-lock",
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					ReadyStatus: &k8sv1beta1.ReadyStatus{
+						Port: &readyPort,
+					},
 				},
 			},
-			instance: &k8sv1alpha1.NginxIngressController{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      name,
-					Namespace: namespace,
-				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					NginxPlus: true,
-				},
+			expected: []string{
+				"-nginx-configmaps=my-nginx-ingress/my-nginx-ingress",
+				"-default-server-tls-secret=my-nginx-ingress/my-nginx-ingress",
+				"-ready-status-port=9091",
+				"-leader-election-lock-name=my-nginx-ingress-lock",
 			},
-			expected: true,
 		},
 		{
-			container: corev1.Container{
-				Args: []string{
-					fmt.Sprintf("-nginx-configmaps=%v/%v", namespace, name),
-					"-default-server-tls-secret=default/mysecret",
-					"-nginx-plus",
-					"-leader-election-lock-name=my-nginx-ingress-lock",
-				},
-			},
-			instance: &k8sv1alpha1.NginxIngressController{
+			instance: &k8sv1beta1.NginxIngressController{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      name,
 					Namespace: namespace,
 				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					NginxPlus:     true,
-					DefaultSecret: "my-namespace/my-secret",
-				},
-			},
-			expected: true,
-		},
-		{
-			container: corev1.Container{
-				Args: []string{
-					fmt.Sprintf(
-						"-nginx-configmaps=%v/%v", namespace, name),
-					"-default-server-tls-secret=default/mysecret",
-					"-nginx-plus",
-					"-leader-election-lock-name=my-nginx-ingress-lock",
-					"-enable-custom-resources=false",
+				Spec: k8sv1beta1.NginxIngressControllerSpec{
+					ReadyStatus: &k8sv1beta1.ReadyStatus{
+						Enable: &disable,
+						Port:   &readyPort,
+					},
 				},
 			},
-			instance: &k8sv1alpha1.NginxIngressController{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      name,
-					Namespace: namespace,
-				},
-				Spec: k8sv1alpha1.NginxIngressControllerSpec{
-					NginxPlus:     true,
-					DefaultSecret: "my-namespace/my-secret",
-				},
+			expected: []string{
+				"-nginx-configmaps=my-nginx-ingress/my-nginx-ingress",
+				"-default-server-tls-secret=my-nginx-ingress/my-nginx-ingress",
+				"-ready-status=false",
+				"-leader-election-lock-name=my-nginx-ingress-lock",
 			},
-			expected: true,
 		},
 	}
 
 	for _, test := range tests {
-		result := hasDifferentArguments(test.container, test.instance)
+		result := generatePodArgs(test.instance)
 		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("hasDifferentArguments(%+v, %+v) mismatch (-want +got):\n%s", test.container, test.instance, diff)
+			t.Errorf("generatePodArgs(%+v) mismatch (-want +got):\n%s", test.instance, diff)
 		}
 	}
 }
@@ -28,6 +28,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apixv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -39,6 +42,7 @@ import (
 	secv1 "github.com/openshift/api/security/v1"
 
 	k8sv1alpha1 "github.com/nginxinc/nginx-ingress-operator/api/v1alpha1"
+	k8sv1beta1 "github.com/nginxinc/nginx-ingress-operator/api/v1beta1"
 	"github.com/nginxinc/nginx-ingress-operator/controllers"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	//+kubebuilder:scaffold:imports
@@ -60,6 +64,8 @@ func printVersion() {
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(k8sv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(k8sv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apixv1.AddToScheme(scheme))
 
 	//+kubebuilder:scaffold:scheme
 }
@@ -68,8 +74,22 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var caName string
+	var crdsDir string
+	var pruneCRDs bool
+	var namespaced bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&caName, "ca-name", "nginx-ingress-operator-ca",
+		"The name of the Secret and the ConfigMap of the certificate authority managed by the Operator, in the namespace of the Operator.")
+	flag.StringVar(&crdsDir, "crds-dir", "",
+		"The directory with the manifests of the NGINX Ingress Controller CRDs. The CRDs embedded in the Operator are installed if not set.")
+	flag.BoolVar(&pruneCRDs, "prune-crds", false,
+		"Delete the NGINX Ingress Controller CRDs installed by the Operator when no NginxIngressController needs them "+
+			"and no resources of their kinds exist.")
+	flag.BoolVar(&namespaced, "namespaced", false,
+		"Run the Operator without write access to the cluster-scoped resources, in the namespaces of WATCH_NAMESPACE. "+
+			"The CRDs, ClusterRoles, ClusterRoleBindings, IngressClasses and SCC must be installed by the cluster administrator.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -105,6 +125,11 @@ func main() {
 			"the manager will watch and manage resources in all Namespaces")
 	}
 
+	if namespaced && watchNamespace == "" {
+		setupLog.Error(fmt.Errorf("WATCH_NAMESPACE must be set in namespaced mode"), "invalid configuration")
+		os.Exit(1)
+	}
+
 	options := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -144,15 +169,76 @@ func main() {
 		utilruntime.Must(secv1.AddToScheme(scheme))
 	}
 
+	var ca *controllers.CertificateAuthority
+	if operatorNamespace := os.Getenv("OPERATOR_NAMESPACE"); operatorNamespace != "" {
+		ca = &controllers.CertificateAuthority{
+			Client:    mgr.GetClient(),
+			Reader:    mgr.GetAPIReader(),
+			Namespace: operatorNamespace,
+			Name:      caName,
+			Log:       ctrl.Log.WithName("certificate-authority"),
+		}
+	} else {
+		setupLog.Info("OPERATOR_NAMESPACE is not set, the Operator CA is not available")
+	}
+
+	apixClient, err := apixv1client.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create client for CRDs")
+		os.Exit(1)
+	}
+
+	// The shared cluster-scoped resources are installed by the cluster administrator in namespaced mode.
+	var sharedResources *controllers.SharedResourcesReconciler
+	if namespaced {
+		setupLog.Info("manager set up in namespaced mode, the cluster-scoped resources are only verified")
+	} else {
+		sharedResources = &controllers.SharedResourcesReconciler{
+			Client:    mgr.GetClient(),
+			CRDClient: apixClient.CustomResourceDefinitions(),
+			Recorder:  mgr.GetEventRecorderFor("nginx-ingress-operator"),
+			CRDsDir:   crdsDir,
+			PruneCRDs: pruneCRDs,
+		}
+		if err = sharedResources.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SharedResources")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.NginxIngressControllerReconciler{
-		Client:       mgr.GetClient(),
-		Scheme:       mgr.GetScheme(),
-		SccAPIExists: sccAPIExists,
-		Mgr:          mgr,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		SccAPIExists:    sccAPIExists,
+		Mgr:             mgr,
+		Recorder:        mgr.GetEventRecorderFor("nginx-ingress-operator"),
+		CA:              ca,
+		SharedResources: sharedResources,
+		Namespaced:      namespaced,
+		Reader:          mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NginxIngressController")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&k8sv1beta1.NginxIngressController{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NginxIngressController")
+			os.Exit(1)
+		}
+	}
+	// The storage version migration updates the NginxIngressController CRD, so it is left to the cluster administrator in
+	// namespaced mode.
+	if !namespaced {
+		if err := mgr.Add(&controllers.StorageVersionMigrator{
+			Client:    mgr.GetClient(),
+			Reader:    mgr.GetAPIReader(),
+			CRDClient: apixClient.CustomResourceDefinitions(),
+			Log:       ctrl.Log.WithName("storage-version-migrator"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up storage version migration")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")